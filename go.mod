@@ -1,5 +1,9 @@
 module github.com/winlabs/gorc
 
-go 1.14
+go 1.18
 
-require github.com/winlabs/gowin32 v0.0.0-20210302152218-c9e40aa88058
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/winlabs/gowin32 v0.0.0-20210302152218-c9e40aa88058
+	gopkg.in/yaml.v3 v3.0.1
+)
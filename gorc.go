@@ -17,37 +17,583 @@
 package main
 
 import (
-	"github.com/winlabs/gowin32"
+	"github.com/winlabs/gorc/rc"
 
+	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 )
 
+type defineFlags map[string]string
+
+func (defines defineFlags) String() string {
+	return ""
+}
+
+func (defines defineFlags) Set(value string) error {
+	i := strings.IndexByte(value, '=')
+	if i <= 0 {
+		return errors.New("definition must have the form name=value")
+	}
+	defines[value[:i]] = value[i+1:]
+	return nil
+}
+
+type overrideFlags []rc.Override
+
+func (overrides *overrideFlags) String() string {
+	return ""
+}
+
+func (overrides *overrideFlags) Set(value string) error {
+	override, err := rc.ParseOverride(value)
+	if err != nil {
+		return err
+	}
+	*overrides = append(*overrides, override)
+	return nil
+}
+
+type selectorFlags []rc.ResourceSelector
+
+func (selectors *selectorFlags) String() string {
+	return ""
+}
+
+func (selectors *selectorFlags) Set(value string) error {
+	sel, err := rc.ParseResourceSelector(value)
+	if err != nil {
+		return err
+	}
+	*selectors = append(*selectors, sel)
+	return nil
+}
+
+type policyFlags []rc.PolicyRule
+
+func (policies *policyFlags) String() string {
+	return ""
+}
+
+func (policies *policyFlags) Set(value string) error {
+	rule, err := rc.ParsePolicyRule(value)
+	if err != nil {
+		return err
+	}
+	*policies = append(*policies, rule)
+	return nil
+}
+
+type ruleFlags map[string]rc.LintLevel
+
+func (levels ruleFlags) String() string {
+	return ""
+}
+
+func (levels ruleFlags) Set(value string) error {
+	name, level, err := rc.ParseLintSetting(value)
+	if err != nil {
+		return err
+	}
+	levels[name] = level
+	return nil
+}
+
 var (
-	discard = flag.Bool("discard", false, "discard any existing resources in the executable")
+	defines    = make(defineFlags)
+	overrides  overrideFlags
+	removals   selectorFlags
+	policies   policyFlags
+	lintLevels = make(ruleFlags)
+
+	arch         = flag.String("arch", defaultArch(), "the architecture of a .syso file")
+	discard      = flag.Bool("discard", false, "discard any existing resources in the executable")
+	outputFile   = flag.String("o", "", "write the updated executable to the given file instead of replacing it")
+	backupSuffix = flag.String("backup", "", "keep a copy of the replaced executable with the given suffix, such as .bak")
+	goPackageDir = flag.String("gopackage", "", "generate a Go package with resource accessors in the given directory")
+	verbose      = flag.Bool("v", false, "print the overrides given by -set as they are applied")
+	dryRun       = flag.Bool("dry-run", false, "print the changes to the executable without making them")
+	jsonOutput   = flag.Bool("json", false, "print the results of -dry-run, verify or lint as JSON")
+	strict       = flag.Bool("strict", false, "with verify, also report resources that the resource files do not describe")
+	jobs         = flag.Int("j", runtime.NumCPU(), "the number of targets in a batch file to work on at once")
 )
 
+func defaultArch() string {
+	if goarch := os.Getenv("GOARCH"); goarch != "" {
+		return goarch
+	}
+	return runtime.GOARCH
+}
+
+func init() {
+	flag.Var(defines, "D", "define a template variable as name=value (may be repeated)")
+	flag.Var(&overrides, "set", "set a field of the resource file as path=value, such as version.fileVersion=1.2.3.4 "+
+		"(may be repeated)")
+	flag.Var(&removals, "remove", "remove resources from the executable, selected as TYPE[/NAME[/LANGUAGE]] "+
+		"(may be repeated)")
+	flag.Var(&policies, "policy", "resolve conflicts with resources in the executable as [SELECTOR=]POLICY, where "+
+		"POLICY is replace, keep-existing or fail-on-conflict (may be repeated)")
+	flag.Var(lintLevels, "rule", "set the level of a lint rule as name=level, where level is off, warning or error "+
+		"(may be repeated)")
+}
+
+// formatSourceError formats an error in the resource file with the file:line:col position of the value that caused
+// it, when that position is known.  Positions that name another file, such as a base file, are reported in it.
+func formatSourceError(fileName string, sourceMap rc.SourceMap, err error) string {
+	if pathErr, ok := err.(*rc.PathError); ok {
+		if pos, ok := sourceMap.Lookup(pathErr.Path); ok {
+			if pos.File != "" {
+				fileName = pos.File
+			}
+			// values set on the command line have no line number
+			if pos.Line == 0 {
+				return fmt.Sprintf("%s: %s (at %s)", fileName, pathErr.Err, pathErr.Path)
+			}
+			return fmt.Sprintf("%s:%s: %s (at %s)", fileName, pos, pathErr.Err, pathErr.Path)
+		}
+		return fmt.Sprintf("%s: %s (at %s)", fileName, pathErr.Err, pathErr.Path)
+	} else if syntaxErr, ok := err.(*rc.SyntaxError); ok {
+		if syntaxErr.Pos.File != "" {
+			fileName = syntaxErr.Pos.File
+		}
+		if syntaxErr.Pos.Line == 0 {
+			return fmt.Sprintf("%s: %s", fileName, syntaxErr.Msg)
+		}
+		return fmt.Sprintf("%s:%s: %s", fileName, syntaxErr.Pos, syntaxErr.Msg)
+	}
+	return fmt.Sprintf("%s: %s", fileName, err)
+}
+
+// reportSourceErrors prints each error in the resource files on its own line, ordered by position in the files.
+// Errors without a known position, as in TOML files, follow in JSON pointer order.
+func reportSourceErrors(w io.Writer, fileName string, sourceMap rc.SourceMap, what string, err error) {
+	errs, ok := err.(rc.ErrorList)
+	if !ok {
+		errs = rc.ErrorList{err}
+	}
+	type sourceError struct {
+		pos   rc.Position
+		known bool
+		path  string
+		err   error
+	}
+	sorted := make([]sourceError, 0, len(errs))
+	for _, e := range errs {
+		entry := sourceError{err: e}
+		if pathErr, ok := e.(*rc.PathError); ok {
+			entry.path = pathErr.Path
+			entry.pos, entry.known = sourceMap.Lookup(pathErr.Path)
+		}
+		sorted = append(sorted, entry)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.known != b.known {
+			return a.known
+		}
+		if a.pos.File != b.pos.File {
+			return a.pos.File < b.pos.File
+		}
+		if a.pos.Line != b.pos.Line {
+			return a.pos.Line < b.pos.Line
+		}
+		if a.pos.Column != b.pos.Column {
+			return a.pos.Column < b.pos.Column
+		}
+		return a.path < b.path
+	})
+	fmt.Fprintf(w, "%s: %s\n", what, fileName)
+	for _, entry := range sorted {
+		fmt.Fprintf(w, "  %s\n", formatSourceError(fileName, sourceMap, entry.err))
+	}
+}
+
+func formatFieldValue(value *string) string {
+	if value == nil {
+		return "(none)"
+	}
+	return fmt.Sprintf("%q", *value)
+}
+
+// printChanges prints the changes to the resources of an executable that -dry-run found, as text or as JSON.
+func printChanges(w io.Writer, fileName string, changes []rc.ResourceChange) {
+	if *jsonOutput {
+		if changes == nil {
+			changes = []rc.ResourceChange{}
+		}
+		data, err := json.MarshalIndent(map[string]interface{}{"file": fileName, "changes": changes}, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintf(w, "%s\n", data)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "no changes to resources in %s\n", fileName)
+		return
+	}
+	fmt.Fprintf(w, "changes to resources in %s:\n", fileName)
+	for _, change := range changes {
+		fmt.Fprintf(w, "  %s: %s\n", change.Resource, change.Change)
+		for _, field := range change.Fields {
+			fmt.Fprintf(w, "    %s: %s -> %s\n", field.Field, formatFieldValue(field.Old), formatFieldValue(field.New))
+		}
+	}
+}
+
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: gorc file.json file.exe\n")
+	fmt.Fprintf(os.Stderr, "usage: gorc resources.{json,yaml,toml} [overlay.{json,yaml,toml} ...] "+
+		"{file.exe,file.res,file.syso}\n")
+	fmt.Fprintf(os.Stderr, "       gorc schema\n")
+	fmt.Fprintf(os.Stderr, "       gorc batch batch.{json,yaml,toml}\n")
+	fmt.Fprintf(os.Stderr, "       gorc verify resources.{json,yaml,toml} [overlay.{json,yaml,toml} ...] file.exe\n")
+	fmt.Fprintf(os.Stderr, "       gorc lint resources.{json,yaml,toml} [overlay.{json,yaml,toml} ...] file.exe\n")
 	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "lint rules:\n")
+	for _, rule := range rc.LintRules() {
+		fmt.Fprintf(os.Stderr, "  %s (%s)\n    \t%s\n", rule.Name, rule.Level, rule.Description)
+	}
 	os.Exit(2)
 }
 
+// outputFormat returns the format in which to write the resources, chosen by the extension of the output file.
+func outputFormat(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".res":
+		return "res"
+	case ".syso":
+		return "syso"
+	default:
+		return "exe"
+	}
+}
+
+// target is a file to which resources are written, together with the resource files and settings for it.
+type target struct {
+	resourceFileNames []string
+	fileName          string
+	outputFileName    string
+	sourceDir         string
+	defines           map[string]string
+	overrides         []rc.Override
+}
+
+// load loads the resources for a target, reporting errors to stderr.  It returns nil if it fails.
+func load(t *target, stderr io.Writer) *rc.ResourceSet {
+	format := outputFormat(t.fileName)
+	options := rc.LoadOptions{
+		Defines:   t.defines,
+		SourceDir: t.sourceDir,
+		Overrides: t.overrides,
+	}
+	if *verbose {
+		options.Verbose = stderr
+	}
+	if format == "exe" {
+		options.TargetFileName = t.fileName
+	}
+	resourceFileName := t.resourceFileNames[len(t.resourceFileNames)-1]
+	set, sourceMap, err := rc.LoadResourceFiles(t.resourceFileNames, &options)
+	if err != nil {
+		if _, ok := err.(*os.PathError); ok {
+			fmt.Fprintf(stderr, "failed to open resource file (%s)\n", err)
+		} else if _, ok := err.(*rc.SyntaxError); ok {
+			message := formatSourceError(resourceFileName, sourceMap, err)
+			fmt.Fprintf(stderr, "failed to parse resource file: %s\n", message)
+		} else {
+			reportSourceErrors(stderr, resourceFileName, sourceMap, "invalid resources in resource file", err)
+		}
+		return nil
+	}
+	return set
+}
+
+// compile loads the resources for a target and writes them to it, reporting errors to stderr and the changes found
+// by -dry-run to stdout.  It returns the resources, or nil if it fails.
+func compile(t *target, stdout io.Writer, stderr io.Writer) *rc.ResourceSet {
+	format := outputFormat(t.fileName)
+	if format != "exe" && (t.outputFileName != "" || *backupSuffix != "" || len(removals) > 0 ||
+		len(policies) > 0 || *dryRun) {
+		fmt.Fprintf(stderr, "-o, -backup, -remove, -policy and -dry-run apply only to executable files\n")
+		return nil
+	}
+	set := load(t, stderr)
+	if set == nil {
+		return nil
+	}
+
+	var err error
+	switch format {
+	case "res", "syso":
+		var buf bytes.Buffer
+		if format == "res" {
+			err = set.WriteRES(&buf)
+		} else {
+			err = set.WriteSyso(&buf, *arch)
+		}
+		if err == nil {
+			err = ioutil.WriteFile(t.fileName, buf.Bytes(), 0666)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to write resources to file: %s (%s)\n", t.fileName, err)
+			return nil
+		}
+	default:
+		var timestamp int64
+		if epoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok {
+			if timestamp, err = rc.ParseSourceDateEpoch(epoch); err != nil {
+				fmt.Fprintf(stderr, "%s\n", err)
+				return nil
+			}
+		}
+		writeOptions := rc.WriteOptions{
+			Discard:        *discard,
+			Timestamp:      uint32(timestamp),
+			OutputFileName: t.outputFileName,
+			BackupSuffix:   *backupSuffix,
+			Remove:         removals,
+			Policies:       policies,
+		}
+		if *dryRun {
+			changes, err := set.DiffExecutable(t.fileName, &writeOptions)
+			if err != nil {
+				fmt.Fprintf(stderr, "%s\n", err)
+				return nil
+			}
+			printChanges(stdout, t.fileName, changes)
+			return set
+		}
+		if err := set.WriteExecutable(t.fileName, &writeOptions); err != nil {
+			fmt.Fprintf(stderr, "%s\n", err)
+			return nil
+		}
+	}
+	return set
+}
+
+// printVerification prints the differences that verify found between the resources and an executable, as text or
+// as JSON.
+func printVerification(w io.Writer, fileName string, changes []rc.ResourceChange) {
+	if *jsonOutput {
+		if changes == nil {
+			changes = []rc.ResourceChange{}
+		}
+		result := map[string]interface{}{"file": fileName, "ok": len(changes) == 0, "differences": changes}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintf(w, "%s\n", data)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "%s contains the expected resources\n", fileName)
+		return
+	}
+	fmt.Fprintf(w, "%s does not contain the expected resources:\n", fileName)
+	for _, change := range changes {
+		fmt.Fprintf(w, "  %s: %s\n", change.Resource, change.Change)
+		for _, field := range change.Fields {
+			fmt.Fprintf(w, "    %s: expected %s, found %s\n", field.Field, formatFieldValue(field.New),
+				formatFieldValue(field.Old))
+		}
+	}
+}
+
+// verify checks that the executable named by a target contains the resources from its resource files.  It returns
+// 0 if it does, 1 if it does not and 2 if the check fails.
+func verify(t *target) int {
+	if outputFormat(t.fileName) != "exe" {
+		fmt.Fprintf(os.Stderr, "verify only applies to executable files\n")
+		return 2
+	}
+	set := load(t, os.Stderr)
+	if set == nil {
+		return 2
+	}
+	changes, err := set.VerifyExecutable(t.fileName, &rc.VerifyOptions{Strict: *strict})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 2
+	}
+	printVerification(os.Stdout, t.fileName, changes)
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// printFindings prints the problems that lint found in the version information for an executable, as text or as
+// JSON.
+func printFindings(w io.Writer, fileName string, findings []rc.LintFinding) {
+	if *jsonOutput {
+		if findings == nil {
+			findings = []rc.LintFinding{}
+		}
+		result := map[string]interface{}{"file": fileName, "findings": findings}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintf(w, "%s\n", data)
+		return
+	}
+	errorCount, warningCount := 0, 0
+	for _, finding := range findings {
+		fmt.Fprintf(w, "%s: %s: %s: %s\n", fileName, finding.Level, finding.Rule, finding.Message)
+		if finding.Level == rc.LintError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+	fmt.Fprintf(w, "%s: %d errors, %d warnings\n", fileName, errorCount, warningCount)
+}
+
+// lint checks the version information in the resources for a target against the lint rules.  It returns 0 if there
+// are no findings at the error level, 1 if there are and 2 if the check fails.
+func lint(t *target) int {
+	if outputFormat(t.fileName) != "exe" {
+		fmt.Fprintf(os.Stderr, "lint only applies to executable files\n")
+		return 2
+	}
+	set := load(t, os.Stderr)
+	if set == nil {
+		return 2
+	}
+	options := rc.LintOptions{Levels: lintLevels}
+	findings, err := set.Lint(t.fileName, &options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 2
+	}
+	printFindings(os.Stdout, t.fileName, findings)
+	for _, finding := range findings {
+		if finding.Level == rc.LintError {
+			return 1
+		}
+	}
+	return 0
+}
+
+// batchResult holds the output of one target in a batch until it can be printed in order.
+type batchResult struct {
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+	ok     bool
+	done   chan struct{}
+}
+
+// runBatch writes the resources for every target in a batch file, working on up to -j targets at a time, and prints
+// the output of each target in the order of the batch file.  It returns the exit status: 0 if every target succeeded
+// and 2 if any failed or the batch file is invalid.
+func runBatch(batchFileName string, stdout io.Writer, stderr io.Writer) int {
+	batchTargets, sourceMap, err := rc.LoadBatchFile(batchFileName)
+	if err != nil {
+		if _, ok := err.(*os.PathError); ok {
+			fmt.Fprintf(stderr, "failed to open batch file (%s)\n", err)
+		} else if _, ok := err.(*rc.SyntaxError); ok {
+			message := formatSourceError(batchFileName, sourceMap, err)
+			fmt.Fprintf(stderr, "failed to parse batch file: %s\n", message)
+		} else {
+			reportSourceErrors(stderr, batchFileName, sourceMap, "invalid batch file", err)
+		}
+		return 2
+	}
+
+	results := make([]*batchResult, len(batchTargets))
+	for i := range results {
+		results[i] = &batchResult{done: make(chan struct{})}
+	}
+	go func() {
+		semaphore := make(chan struct{}, *jobs)
+		for i, batchTarget := range batchTargets {
+			semaphore <- struct{}{}
+			go func(batchTarget *rc.BatchTarget, result *batchResult) {
+				defer func() {
+					<-semaphore
+					close(result.done)
+				}()
+				// definitions on the command line take precedence over those in the batch file
+				targetDefines := make(map[string]string)
+				for _, definitions := range []map[string]string{batchTarget.Defines, defines} {
+					for name, value := range definitions {
+						targetDefines[name] = value
+					}
+				}
+				t := &target{
+					resourceFileNames: batchTarget.ResourceFileNames,
+					fileName:          batchTarget.FileName,
+					outputFileName:    batchTarget.OutputFileName,
+					defines:           targetDefines,
+					overrides:         append(append([]rc.Override(nil), batchTarget.Overrides...), overrides...),
+				}
+				result.ok = compile(t, &result.stdout, &result.stderr) != nil
+			}(batchTarget, results[i])
+		}
+	}()
+
+	failed := 0
+	for i, result := range results {
+		<-result.done
+		stdout.Write(result.stdout.Bytes())
+		stderr.Write(result.stderr.Bytes())
+		if result.ok {
+			fmt.Fprintf(stderr, "%s: ok\n", batchTargets[i].FileName)
+		} else {
+			fmt.Fprintf(stderr, "%s: failed\n", batchTargets[i].FileName)
+			failed++
+		}
+	}
+	fmt.Fprintf(stderr, "%d of %d targets succeeded\n", len(results)-failed, len(results))
+	if failed > 0 {
+		return 2
+	}
+	return 0
+}
+
 func main() {
 	flag.Usage = usage
 	flag.Parse()
 
 	args := flag.Args()
+	if len(args) == 1 && args[0] == "schema" {
+		os.Stdout.Write(rc.EncodeResourceSchema())
+		return
+	}
+	if len(args) == 2 && args[0] == "batch" {
+		if *outputFile != "" || *goPackageDir != "" {
+			fmt.Fprintf(os.Stderr, "-o and -gopackage cannot be used with a batch file\n")
+			os.Exit(2)
+		}
+		if *jobs < 1 {
+			fmt.Fprintf(os.Stderr, "-j must be at least 1\n")
+			os.Exit(2)
+		}
+		os.Exit(runBatch(args[1], os.Stdout, os.Stderr))
+	}
+	var command string
+	if len(args) > 0 && (args[0] == "verify" || args[0] == "lint") {
+		command, args = args[0], args[1:]
+	}
 	if len(args) < 2 {
 		usage()
 	}
 
+	resourceFileNames, targetFileName := args[:len(args)-1], args[len(args)-1]
+	resourceFileName := resourceFileNames[len(resourceFileNames)-1]
 	var sourceDir string
-	if filepath.IsAbs(args[0]) {
-		sourceDir = filepath.Dir(args[0])
+	if filepath.IsAbs(resourceFileName) {
+		sourceDir = filepath.Dir(resourceFileName)
 	} else {
 		if curDir, err := os.Getwd(); err != nil {
 			fmt.Fprintf(os.Stderr, "failed to get current working directory (%s)", err)
@@ -57,41 +603,28 @@ func main() {
 		}
 	}
 
-	jsonFile, err := os.Open(args[0])
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to open JSON file: %s (%s)\n", args[0], err)
-		os.Exit(2)
-	}
-	defer jsonFile.Close()
-	decoder := json.NewDecoder(jsonFile)
-
-	var jsonData map[string]interface{}
-	if err := decoder.Decode(&jsonData); err != nil {
-		fmt.Fprintf(os.Stderr, "failed to parse JSON file: %s (%s)\n", args[0], err)
-		os.Exit(2)
+	t := &target{
+		resourceFileNames: resourceFileNames,
+		fileName:          targetFileName,
+		outputFileName:    *outputFile,
+		sourceDir:         sourceDir,
+		defines:           defines,
+		overrides:         overrides,
 	}
-	language, resources, err := ParseResources(jsonData, sourceDir)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "invalid resources in JSON file: %s (%s)\n", args[0], err)
-		os.Exit(2)
+	switch command {
+	case "verify":
+		os.Exit(verify(t))
+	case "lint":
+		os.Exit(lint(t))
 	}
-
-	update, err := gowin32.NewResourceUpdate(args[1], *discard)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to open executable file for resource update: %s (%s)\n", args[1], err)
+	set := compile(t, os.Stdout, os.Stderr)
+	if set == nil {
 		os.Exit(2)
 	}
-	defer update.Close()
-
-	for _, res := range resources {
-		if err := update.Update(res.Type, gowin32.IntResourceId(res.Id), language, res.Data); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to update resource (%s)\n", err)
+	if *goPackageDir != "" && !*dryRun {
+		if err := rc.GenerateGoPackage(*goPackageDir, set.Language, set.Resources); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to generate Go package: %s (%s)\n", *goPackageDir, err)
 			os.Exit(2)
 		}
 	}
-
-	if err := update.Save(); err != nil {
-		fmt.Fprintf(os.Stderr, "failed to save updated resources to executable file: %s (%s)\n", args[1], err)
-		os.Exit(2)
-	}
 }
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/winlabs/gorc/rc"
+
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunBatchExitStatus(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"good.json": `{"version": {"fileVersion": "1.2.3.4"}}`,
+		"bad.json":  `{"version": {"fileVersion": "not a version"}}`,
+		"ok.json":   `{"targets": {"a.res": "good.json", "b.res": "good.json"}}`,
+		"fail.json": `{"targets": {"a.res": "good.json", "b.res": "bad.json"}}`,
+		"dup.json":  `{"resources": "good.json", "targets": [{"file": "a.res"}, {"file": "a.res"}]}`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		BatchFile string
+		Status    int
+		Summary   string
+	}{
+		{"ok.json", 0, "2 of 2 targets succeeded"},
+		{"fail.json", 2, "1 of 2 targets succeeded"},
+		{"dup.json", 2, "invalid batch file"},
+		{"missing.json", 2, "failed to open batch file"},
+	}
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		status := runBatch(filepath.Join(dir, test.BatchFile), &stdout, &stderr)
+		if status != test.Status || !strings.Contains(stderr.String(), test.Summary) {
+			t.Errorf("%s: exit status %d, expected %d with %q:\n%s", test.BatchFile, status, test.Status, test.Summary,
+				stderr.String())
+		}
+	}
+}
+
+func TestPrintChanges(t *testing.T) {
+	oldVersion, newVersion := "1.0.0.0", "1.2.0.0"
+	changes := []rc.ResourceChange{
+		{
+			Resource: "RT_VERSION/1/0x0409",
+			Change:   rc.ResourceChanged,
+			Fields:   []rc.FieldChange{{Field: "fileVersion", Old: &oldVersion, New: &newVersion}},
+		},
+		{Resource: "RT_RCDATA/CONFIG/0x0409", Change: rc.ResourceRemoved},
+	}
+	defer func(saved bool) { *jsonOutput = saved }(*jsonOutput)
+	for _, test := range []struct {
+		Json     bool
+		Changes  []rc.ResourceChange
+		Expected string
+	}{
+		{false, changes, `changes to resources in hello.exe:
+  RT_VERSION/1/0x0409: changed
+    fileVersion: "1.0.0.0" -> "1.2.0.0"
+  RT_RCDATA/CONFIG/0x0409: removed
+`},
+		{false, nil, "no changes to resources in hello.exe\n"},
+		{true, changes, `{
+  "changes": [
+    {
+      "resource": "RT_VERSION/1/0x0409",
+      "change": "changed",
+      "fields": [
+        {
+          "field": "fileVersion",
+          "old": "1.0.0.0",
+          "new": "1.2.0.0"
+        }
+      ]
+    },
+    {
+      "resource": "RT_RCDATA/CONFIG/0x0409",
+      "change": "removed"
+    }
+  ],
+  "file": "hello.exe"
+}
+`},
+		{true, nil, `{
+  "changes": [],
+  "file": "hello.exe"
+}
+`},
+	} {
+		*jsonOutput = test.Json
+		var buf bytes.Buffer
+		printChanges(&buf, "hello.exe", test.Changes)
+		if buf.String() != test.Expected {
+			t.Errorf("json %v: got\n%s\nexpected\n%s", test.Json, buf.String(), test.Expected)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	FVIRTKEY  = 0x01
+	FNOINVERT = 0x02
+	FSHIFT    = 0x04
+	FCONTROL  = 0x08
+	FALT      = 0x10
+
+	acceleratorLastEntry = 0x80
+)
+
+// Virtual-key codes accepted as the final key of a chord, keyed by upper-case name.  Letters and digits are looked up
+// separately since their virtual-key codes are equal to their ASCII values.
+var virtualKeys = map[string]uint16{
+	"BACKSPACE":   0x08,
+	"BACK":        0x08,
+	"TAB":         0x09,
+	"CLEAR":       0x0C,
+	"ENTER":       0x0D,
+	"RETURN":      0x0D,
+	"PAUSE":       0x13,
+	"CAPSLOCK":    0x14,
+	"ESC":         0x1B,
+	"ESCAPE":      0x1B,
+	"SPACE":       0x20,
+	"PAGEUP":      0x21,
+	"PGUP":        0x21,
+	"PRIOR":       0x21,
+	"PAGEDOWN":    0x22,
+	"PGDN":        0x22,
+	"NEXT":        0x22,
+	"END":         0x23,
+	"HOME":        0x24,
+	"LEFT":        0x25,
+	"UP":          0x26,
+	"RIGHT":       0x27,
+	"DOWN":        0x28,
+	"SELECT":      0x29,
+	"PRINT":       0x2A,
+	"EXECUTE":     0x2B,
+	"PRINTSCREEN": 0x2C,
+	"SNAPSHOT":    0x2C,
+	"INS":         0x2D,
+	"INSERT":      0x2D,
+	"DEL":         0x2E,
+	"DELETE":      0x2E,
+	"HELP":        0x2F,
+	"APPS":        0x5D,
+	"NUMPAD0":     0x60,
+	"NUMPAD1":     0x61,
+	"NUMPAD2":     0x62,
+	"NUMPAD3":     0x63,
+	"NUMPAD4":     0x64,
+	"NUMPAD5":     0x65,
+	"NUMPAD6":     0x66,
+	"NUMPAD7":     0x67,
+	"NUMPAD8":     0x68,
+	"NUMPAD9":     0x69,
+	"MULTIPLY":    0x6A,
+	"ADD":         0x6B,
+	"SEPARATOR":   0x6C,
+	"SUBTRACT":    0x6D,
+	"DECIMAL":     0x6E,
+	"DIVIDE":      0x6F,
+	"F1":          0x70,
+	"F2":          0x71,
+	"F3":          0x72,
+	"F4":          0x73,
+	"F5":          0x74,
+	"F6":          0x75,
+	"F7":          0x76,
+	"F8":          0x77,
+	"F9":          0x78,
+	"F10":         0x79,
+	"F11":         0x7A,
+	"F12":         0x7B,
+	"F13":         0x7C,
+	"F14":         0x7D,
+	"F15":         0x7E,
+	"F16":         0x7F,
+	"F17":         0x80,
+	"F18":         0x81,
+	"F19":         0x82,
+	"F20":         0x83,
+	"F21":         0x84,
+	"F22":         0x85,
+	"F23":         0x86,
+	"F24":         0x87,
+	"NUMLOCK":     0x90,
+	"SCROLLLOCK":  0x91,
+	"PLUS":        0xBB,
+	"MINUS":       0xBD,
+	";":           0xBA,
+	"=":           0xBB,
+	",":           0xBC,
+	"-":           0xBD,
+	".":           0xBE,
+	"/":           0xBF,
+	"`":           0xC0,
+	"[":           0xDB,
+	"\\":          0xDC,
+	"]":           0xDD,
+	"'":           0xDE,
+}
+
+type AcceleratorEntry struct {
+	Flags   uint16
+	Key     uint16
+	Command uint16
+}
+
+// ParseKeyChord parses a key chord such as "Ctrl+Shift+S" into accelerator flags and a virtual-key code.  Modifier
+// and key names are case-insensitive, and the final key may also be given by its VK_ constant name.  A trailing plus
+// sign is the key itself, as in "Ctrl++".
+func ParseKeyChord(chord string) (uint16, uint16, error) {
+	var parts []string
+	if chord == "+" {
+		// the key itself is the plus sign, with or without modifiers
+		parts = []string{"PLUS"}
+	} else if strings.HasSuffix(chord, "++") {
+		parts = append(strings.Split(chord[:len(chord)-2], "+"), "PLUS")
+	} else {
+		parts = strings.Split(chord, "+")
+	}
+	flags := uint16(FVIRTKEY)
+	for _, modifier := range parts[:len(parts)-1] {
+		var modifierFlag uint16
+		switch strings.ToUpper(strings.TrimSpace(modifier)) {
+		case "CTRL", "CONTROL":
+			modifierFlag = FCONTROL
+		case "SHIFT":
+			modifierFlag = FSHIFT
+		case "ALT":
+			modifierFlag = FALT
+		default:
+			return 0, 0, errors.New(fmt.Sprintf("invalid modifier %s in key chord %s", modifier, chord))
+		}
+		if flags&modifierFlag != 0 {
+			return 0, 0, errors.New(fmt.Sprintf("repeated modifier %s in key chord %s", modifier, chord))
+		}
+		flags |= modifierFlag
+	}
+	keyName := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(parts[len(parts)-1])), "VK_")
+	if len(keyName) == 1 && (keyName[0] >= 'A' && keyName[0] <= 'Z' || keyName[0] >= '0' && keyName[0] <= '9') {
+		return flags, uint16(keyName[0]), nil
+	}
+	if key, ok := virtualKeys[keyName]; ok {
+		return flags, key, nil
+	}
+	return 0, 0, errors.New(fmt.Sprintf("invalid key in key chord %s", chord))
+}
+
+func EncodeAcceleratorTable(entries []AcceleratorEntry) []byte {
+	var buf bytes.Buffer
+	for i, entry := range entries {
+		flags := entry.Flags
+		if i == len(entries)-1 {
+			flags |= acceleratorLastEntry
+		}
+		// ACCELTABLEENTRY: fFlags, wAnsi, wId and a padding word
+		binary.Write(&buf, binary.LittleEndian, flags)
+		binary.Write(&buf, binary.LittleEndian, entry.Key)
+		binary.Write(&buf, binary.LittleEndian, entry.Command)
+		binary.Write(&buf, binary.LittleEndian, uint16(0))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseKeyChord(t *testing.T) {
+	for _, test := range []struct {
+		Chord string
+		Flags uint16
+		Key   uint16
+	}{
+		{"A", FVIRTKEY, 'A'},
+		{"ctrl+s", FVIRTKEY | FCONTROL, 'S'},
+		{"Ctrl+Shift+Alt+F5", FVIRTKEY | FCONTROL | FSHIFT | FALT, 0x74},
+		{"Control + VK_DELETE", FVIRTKEY | FCONTROL, 0x2E},
+		{"Shift+9", FVIRTKEY | FSHIFT, '9'},
+		{"Ctrl+-", FVIRTKEY | FCONTROL, 0xBD},
+		{"+", FVIRTKEY, 0xBB},
+		{"Ctrl++", FVIRTKEY | FCONTROL, 0xBB},
+		{"Ctrl+Shift++", FVIRTKEY | FCONTROL | FSHIFT, 0xBB},
+		{"Alt+Plus", FVIRTKEY | FALT, 0xBB},
+	} {
+		flags, key, err := ParseKeyChord(test.Chord)
+		if err != nil {
+			t.Errorf("%s: %s", test.Chord, err)
+		} else if flags != test.Flags || key != test.Key {
+			t.Errorf("%s: got flags 0x%02X and key 0x%02X, expected 0x%02X and 0x%02X", test.Chord, flags, key,
+				test.Flags, test.Key)
+		}
+	}
+	for _, chord := range []string{"", "Ctrl+", "Ctrl+Ctrl+S", "Win+S", "Ctrl+F25", "Ctrl+AB", "++"} {
+		if _, _, err := ParseKeyChord(chord); err == nil {
+			t.Errorf("%q was accepted", chord)
+		}
+	}
+}
+
+func TestEncodeAcceleratorTable(t *testing.T) {
+	for _, test := range []struct {
+		Name    string
+		Entries string
+		Data    string
+	}{
+		{
+			Name:    "single entry",
+			Entries: `{"key": "Ctrl+S", "command": 40001}`,
+			Data:    `8900 | 5300 | 419C | 0000`,
+		},
+		{
+			// only the last entry has the 0x80 flag, and every entry is padded to eight bytes
+			Name: "several entries",
+			Entries: `{"key": "F1", "command": 1}, {"key": "Shift+Ctrl++", "command": 2, "noInvert": true},
+				{"key": "Alt+Enter", "command": 65535}`,
+			Data: `0100 | 7000 | 0100 | 0000
+				0F00 | BB00 | 0200 | 0000
+				9100 | 0D00 | FFFF | 0000`,
+		},
+	} {
+		data, err := parseTestResources(t, `{"accelerators": [{"id": 1, "entries": [`+test.Entries+`]}]}`,
+			ResourceTypeAccelerator)
+		if err != nil {
+			t.Errorf("%s: %s", test.Name, err)
+		} else if expected := hexData(t, test.Data); len(data) != 1 || !bytes.Equal(data[0], expected) {
+			t.Errorf("%s: got % X, expected % X", test.Name, data, expected)
+		}
+	}
+}
+
+func TestParseAcceleratorErrors(t *testing.T) {
+	for _, test := range []struct {
+		Json  string
+		Paths []string
+	}{
+		{`{"id": 1, "entries": []}`, []string{"/accelerators/0/entries"}},
+		{`{"id": 1, "entries": [{"key": "Ctrl+S", "command": 1}, {"key": "control+s", "command": 2}]}`,
+			[]string{"/accelerators/0/entries/1/key"}},
+		{`{"id": 1, "entries": [{"key": "Hyper+S", "command": 65536, "noInvert": 1}]}`,
+			[]string{"/accelerators/0/entries/0/command", "/accelerators/0/entries/0/key",
+				"/accelerators/0/entries/0/noInvert"}},
+	} {
+		_, err := parseTestResources(t, `{"accelerators": [`+test.Json+`]}`, ResourceTypeAccelerator)
+		if err == nil {
+			t.Errorf("%s: no error", test.Json)
+		} else if paths := errorPaths(err); !reflect.DeepEqual(paths, test.Paths) {
+			t.Errorf("%s: errors at %q, expected %q (%s)", test.Json, paths, test.Paths, err)
+		}
+	}
+}
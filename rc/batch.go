@@ -0,0 +1,310 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+)
+
+// BatchTarget is one file to which resources are written by a batch file.
+type BatchTarget struct {
+	FileName          string
+	OutputFileName    string // if not empty, the updated executable is written here instead
+	ResourceFileNames []string
+	Defines           map[string]string
+	Overrides         []Override
+}
+
+// batchSettings are the fields that may be given for every target at the top level of a batch file and for each
+// target individually.
+type batchSettings struct {
+	ResourceFileNames []string
+	Defines           map[string]string
+	Overrides         []Override
+}
+
+func parseStringList(fieldName string, value interface{}) ([]string, error) {
+	if text, ok := value.(string); ok {
+		return []string{text}, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fieldError(fieldName, "must specify a string or a list of strings")
+	}
+	result := make([]string, 0, len(list))
+	for _, elem := range list {
+		text, ok := elem.(string)
+		if !ok {
+			return nil, fieldError(fieldName, "must specify a string or a list of strings")
+		}
+		result = append(result, text)
+	}
+	return result, nil
+}
+
+// scalarString converts a scalar value in a batch file to text, so that YAML and TOML files may give numbers and
+// booleans without quotes.
+func scalarString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int64, bool:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+func parseStringMap(fieldName string, value interface{}) (map[string]string, error) {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fieldError(fieldName, "must specify an object")
+	}
+	result := make(map[string]string, len(obj))
+	for key, elem := range obj {
+		text, ok := scalarString(elem)
+		if !ok {
+			return nil, atField(fieldName, fieldError(key, "must specify a string"))
+		}
+		result[key] = text
+	}
+	return result, nil
+}
+
+// parseBatchSetting parses one of the fields in batchSettings, returning false if the field is not one of them.
+func parseBatchSetting(settings *batchSettings, key string, value interface{}, baseDir string) (bool, error) {
+	switch key {
+	case "resources":
+		fileNames, err := parseStringList(key, value)
+		if err != nil {
+			return true, err
+		}
+		for _, fileName := range fileNames {
+			if !filepath.IsAbs(fileName) {
+				fileName = filepath.Join(baseDir, fileName)
+			}
+			settings.ResourceFileNames = append(settings.ResourceFileNames, fileName)
+		}
+	case "define":
+		defines, err := parseStringMap(key, value)
+		if err != nil {
+			return true, err
+		}
+		settings.Defines = defines
+	case "set":
+		values, err := parseStringMap(key, value)
+		if err != nil {
+			return true, err
+		}
+		// the order of the overrides only matters for paths that overlap, so the paths are sorted to make it stable
+		paths := make([]string, 0, len(values))
+		for path := range values {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			settings.Overrides = append(settings.Overrides, Override{Path: path, Value: values[path]})
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// newBatchTarget combines the settings for every target with those of one target, which come after them.
+func newBatchTarget(fileName string, shared *batchSettings, own *batchSettings) *BatchTarget {
+	target := &BatchTarget{
+		FileName:          fileName,
+		ResourceFileNames: append(append([]string(nil), shared.ResourceFileNames...), own.ResourceFileNames...),
+		Defines:           make(map[string]string),
+		Overrides:         append(append([]Override(nil), shared.Overrides...), own.Overrides...),
+	}
+	for _, defines := range []map[string]string{shared.Defines, own.Defines} {
+		for name, value := range defines {
+			target.Defines[name] = value
+		}
+	}
+	return target
+}
+
+func parseBatchTarget(
+	targetJson map[string]interface{},
+	shared *batchSettings,
+	baseDir string) (*BatchTarget, error) {
+	var own batchSettings
+	var fileName, outputFileName string
+	var errs ErrorList
+	for key, value := range targetJson {
+		if ok, err := parseBatchSetting(&own, key, value, baseDir); ok {
+			if err != nil {
+				errs.add(err)
+			}
+			continue
+		}
+		switch key {
+		case "file", "output":
+			text, ok := value.(string)
+			if !ok {
+				errs.add(fieldError(key, "must specify a file name"))
+				continue
+			}
+			if !filepath.IsAbs(text) {
+				text = filepath.Join(baseDir, text)
+			}
+			if key == "file" {
+				fileName = text
+			} else {
+				outputFileName = text
+			}
+		default:
+			errs.add(atField(key, errors.New(fmt.Sprintf("invalid target field %s", key))))
+		}
+	}
+	if _, ok := targetJson["file"]; !ok {
+		errs.add(errors.New("missing field file"))
+	}
+	if len(shared.ResourceFileNames)+len(own.ResourceFileNames) == 0 {
+		errs.add(errors.New("no resource files are given for the target"))
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	target := newBatchTarget(fileName, shared, &own)
+	target.OutputFileName = outputFileName
+	return target, nil
+}
+
+// ParseBatch parses the decoded contents of a batch file, which lists the files to which resources are written.  The
+// top level may give resources, define and set fields that apply to every target, and the targets field is either a
+// list of objects with the same fields together with file and output, or an object that maps each file name to its
+// resource files.  Relative file names are resolved against baseDir.  No two targets may name the same file, as the
+// file to update or as the output.  As with ParseResources, numbers may be given as decoded by encoding/json.
+func ParseBatch(jsonData map[string]interface{}, baseDir string) ([]*BatchTarget, error) {
+	jsonData = normalizeValue(jsonData).(map[string]interface{})
+	var shared batchSettings
+	var targetsObj interface{}
+	var errs ErrorList
+	for key, value := range jsonData {
+		if ok, err := parseBatchSetting(&shared, key, value, baseDir); ok {
+			if err != nil {
+				errs.add(err)
+			}
+			continue
+		}
+		switch key {
+		case "targets":
+			targetsObj = value
+		case "$schema":
+		default:
+			errs.add(atField(key, errors.New(fmt.Sprintf("invalid batch field %s", key))))
+		}
+	}
+
+	var targets []*BatchTarget
+	var pointers [][2]string
+	switch targetsJson := targetsObj.(type) {
+	case []interface{}:
+		for i, targetObj := range targetsJson {
+			targetJson, ok := targetObj.(map[string]interface{})
+			if !ok {
+				errs.add(withPath(errors.New("target must be an object"), fmt.Sprintf("/targets/%d", i)))
+				continue
+			}
+			target, err := parseBatchTarget(targetJson, &shared, baseDir)
+			if err != nil {
+				errs.add(withPath(err, fmt.Sprintf("/targets/%d", i)))
+				continue
+			}
+			targets = append(targets, target)
+			pointers = append(pointers, [2]string{fmt.Sprintf("/targets/%d/file", i), fmt.Sprintf("/targets/%d/output", i)})
+		}
+	case map[string]interface{}:
+		fileNames := make([]string, 0, len(targetsJson))
+		for fileName := range targetsJson {
+			fileNames = append(fileNames, fileName)
+		}
+		sort.Strings(fileNames)
+		for _, fileName := range fileNames {
+			var own batchSettings
+			if _, err := parseBatchSetting(&own, "resources", targetsJson[fileName], baseDir); err != nil {
+				err = errors.New(fmt.Sprintf("target %s must specify a resource file or a list of them", fileName))
+				errs.add(atField("targets", atField(fileName, err)))
+				continue
+			}
+			if len(shared.ResourceFileNames)+len(own.ResourceFileNames) == 0 {
+				err := errors.New("no resource files are given for the target")
+				errs.add(atField("targets", atField(fileName, err)))
+				continue
+			}
+			pointer := "/targets/" + escapeJSONPointer(fileName)
+			pointers = append(pointers, [2]string{pointer, pointer})
+			if !filepath.IsAbs(fileName) {
+				fileName = filepath.Join(baseDir, fileName)
+			}
+			targets = append(targets, newBatchTarget(fileName, &shared, &own))
+		}
+	case nil:
+		errs.add(errors.New("missing field targets"))
+	default:
+		errs.add(fieldError("targets", "must specify a list of objects or an object"))
+	}
+	errs = append(errs, checkBatchFiles(targets, pointers)...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return targets, nil
+}
+
+// checkBatchFiles reports targets that read or write a file that an earlier target also reads or writes, since the
+// targets are processed in parallel and the last one to finish would win.  The pointers give the position of the file
+// and output fields of each target.
+func checkBatchFiles(targets []*BatchTarget, pointers [][2]string) ErrorList {
+	var errs ErrorList
+	users := make(map[string]string)
+	for i, target := range targets {
+		for j, fileName := range []string{target.FileName, target.OutputFileName} {
+			if fileName == "" || j == 1 && fileName == target.FileName {
+				continue
+			}
+			if user, ok := users[fileName]; ok {
+				err := errors.New(fmt.Sprintf("file %s is already used by the target at %s", fileName, user))
+				errs.add(withPath(err, pointers[i][j]))
+				continue
+			}
+			users[fileName] = pointers[i][0]
+		}
+	}
+	return errs
+}
+
+// LoadBatchFile reads a batch file in any of the formats accepted by DecodeResourceFile and parses it with
+// ParseBatch, resolving file names against the directory that contains it.
+func LoadBatchFile(fileName string) ([]*BatchTarget, SourceMap, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	jsonData, sourceMap, err := DecodeResourceFile(fileName, data)
+	if err != nil {
+		return nil, sourceMap, err
+	}
+	targets, err := ParseBatch(jsonData, filepath.Dir(fileName))
+	return targets, sourceMap, err
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// errorPaths returns the sorted JSON pointers of the errors in an ErrorList of PathErrors, with "" for errors that
+// have no pointer.
+func errorPaths(err error) []string {
+	var paths []string
+	list, ok := err.(ErrorList)
+	if !ok {
+		list = ErrorList{err}
+	}
+	for _, e := range list {
+		if pathErr, ok := e.(*PathError); ok {
+			paths = append(paths, pathErr.Path)
+		} else {
+			paths = append(paths, "")
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestParseBatch(t *testing.T) {
+	dir := filepath.FromSlash("/build")
+	path := func(name string) string {
+		return filepath.Join(dir, filepath.FromSlash(name))
+	}
+	tests := []struct {
+		Name     string
+		Batch    map[string]interface{}
+		Expected []*BatchTarget
+	}{
+		{
+			Name: "list",
+			Batch: map[string]interface{}{
+				"resources": "common.json",
+				"define":    map[string]interface{}{"build": int64(77), "channel": "beta"},
+				"set":       map[string]interface{}{"version.fileVersion": "1.0.0.0", "language": "en-US"},
+				"targets": []interface{}{
+					map[string]interface{}{
+						"file":      "bin/a.exe",
+						"output":    "dist/a.exe",
+						"resources": []interface{}{"a.json"},
+						"define":    map[string]interface{}{"channel": "stable"},
+						"set":       map[string]interface{}{"version.stringFileInfo.internalName": "a"},
+					},
+					map[string]interface{}{"file": "bin/b.exe"},
+				},
+			},
+			Expected: []*BatchTarget{
+				{
+					FileName:          path("bin/a.exe"),
+					OutputFileName:    path("dist/a.exe"),
+					ResourceFileNames: []string{path("common.json"), path("a.json")},
+					Defines:           map[string]string{"build": "77", "channel": "stable"},
+					Overrides: []Override{
+						{Path: "language", Value: "en-US"},
+						{Path: "version.fileVersion", Value: "1.0.0.0"},
+						{Path: "version.stringFileInfo.internalName", Value: "a"},
+					},
+				},
+				{
+					FileName:          path("bin/b.exe"),
+					ResourceFileNames: []string{path("common.json")},
+					Defines:           map[string]string{"build": "77", "channel": "beta"},
+					Overrides: []Override{
+						{Path: "language", Value: "en-US"},
+						{Path: "version.fileVersion", Value: "1.0.0.0"},
+					},
+				},
+			},
+		},
+		{
+			Name: "map",
+			Batch: map[string]interface{}{
+				"resources": "common.json",
+				"targets": map[string]interface{}{
+					"bin/b.exe": []interface{}{"b.json", "b2.json"},
+					"bin/a.exe": "a.json",
+				},
+			},
+			Expected: []*BatchTarget{
+				{
+					FileName:          path("bin/a.exe"),
+					ResourceFileNames: []string{path("common.json"), path("a.json")},
+					Defines:           map[string]string{},
+				},
+				{
+					FileName:          path("bin/b.exe"),
+					ResourceFileNames: []string{path("common.json"), path("b.json"), path("b2.json")},
+					Defines:           map[string]string{},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		targets, err := ParseBatch(test.Batch, dir)
+		if err != nil {
+			t.Errorf("%s: %s", test.Name, err)
+		} else if !reflect.DeepEqual(targets, test.Expected) {
+			t.Errorf("%s: got %+v, expected %+v", test.Name, targets, test.Expected)
+		}
+	}
+}
+
+func TestParseBatchErrors(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Batch map[string]interface{}
+		Paths []string
+	}{
+		{"no targets", map[string]interface{}{"resources": "r.json"}, []string{""}},
+		{"bad field", map[string]interface{}{"target": []interface{}{}, "targets": []interface{}{}}, []string{"/target"}},
+		{
+			"bad targets",
+			map[string]interface{}{"targets": []interface{}{
+				"a.exe",
+				map[string]interface{}{"resources": "r.json"},
+				map[string]interface{}{"file": "c.exe"},
+				map[string]interface{}{"file": "d.exe", "resources": "r.json", "icon": "d.ico"},
+			}},
+			[]string{"/targets/0", "/targets/1", "/targets/2", "/targets/3/icon"},
+		},
+		{
+			"same file",
+			map[string]interface{}{"resources": "r.json", "targets": []interface{}{
+				map[string]interface{}{"file": "out/a.syso"},
+				map[string]interface{}{"file": "./out/a.syso"},
+			}},
+			[]string{"/targets/1/file"},
+		},
+		{
+			"same output",
+			map[string]interface{}{"resources": "r.json", "targets": []interface{}{
+				map[string]interface{}{"file": "a.exe", "output": "dist/app.exe"},
+				map[string]interface{}{"file": "b.exe", "output": "dist/app.exe"},
+				map[string]interface{}{"file": "dist/app.exe"},
+			}},
+			[]string{"/targets/1/output", "/targets/2/file"},
+		},
+		{
+			"same file in map",
+			map[string]interface{}{"targets": map[string]interface{}{"a.exe": "r.json", "./a.exe": "s.json"}},
+			[]string{"/targets/a.exe"},
+		},
+	}
+	for _, test := range tests {
+		_, err := ParseBatch(test.Batch, filepath.FromSlash("/build"))
+		if err == nil {
+			t.Errorf("%s: the batch was accepted", test.Name)
+		} else if paths := errorPaths(err); !reflect.DeepEqual(paths, test.Paths) {
+			t.Errorf("%s: errors at %q, expected %q (%s)", test.Name, paths, test.Paths, err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"debug/buildinfo"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GoBuildInfo holds the parts of the runtime/debug build information embedded in a Go binary that are relevant to
+// version resources.
+type GoBuildInfo struct {
+	ModulePath    string
+	ModuleVersion string
+	Revision      string
+	Time          string
+	Modified      bool
+}
+
+// ReadGoBuildInfo reads the build information from a Go executable without running it.
+func ReadGoBuildInfo(fileName string) (*GoBuildInfo, error) {
+	info, err := buildinfo.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	goInfo := GoBuildInfo{
+		ModulePath:    info.Main.Path,
+		ModuleVersion: info.Main.Version,
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			goInfo.Revision = setting.Value
+		case "vcs.time":
+			goInfo.Time = setting.Value
+		case "vcs.modified":
+			goInfo.Modified = setting.Value == "true"
+		}
+	}
+	return &goInfo, nil
+}
+
+var pseudoVersionPattern = regexp.MustCompile(`(^|[-.])\d{14}-[0-9a-f]{12}$`)
+
+// isTaggedModuleVersion reports whether a module version refers to a tagged release.  Development builds and
+// pseudo-versions do not carry a meaningful version number.
+func isTaggedModuleVersion(version string) bool {
+	semver, err := ParseSemanticVersion(version)
+	if err != nil {
+		return false
+	}
+	return !pseudoVersionPattern.MatchString(strings.Join(semver.Prerelease, "."))
+}
+
+type buildInfoOptions struct {
+	ProductVersion bool
+	RevisionKey    string
+	PrivateBuild   bool
+}
+
+func parseBuildInfoOptions(buildInfoObj interface{}) (*buildInfoOptions, error) {
+	options := buildInfoOptions{
+		ProductVersion: true,
+		RevisionKey:    "SourceRevision",
+		PrivateBuild:   true,
+	}
+	if enabled, ok := buildInfoObj.(bool); ok {
+		if !enabled {
+			return nil, nil
+		}
+		return &options, nil
+	}
+	buildInfoJson, ok := buildInfoObj.(map[string]interface{})
+	if !ok {
+		return nil, fieldError("buildInfo", "must specify a boolean or an object")
+	}
+	for key, value := range buildInfoJson {
+		switch key {
+		case "productVersion":
+			if options.ProductVersion, ok = value.(bool); !ok {
+				return nil, atField("buildInfo", fieldError(key, "must specify a boolean"))
+			}
+		case "revisionKey":
+			if options.RevisionKey, ok = value.(string); !ok {
+				return nil, atField("buildInfo", fieldError(key, "must specify a string"))
+			}
+		case "privateBuild":
+			if options.PrivateBuild, ok = value.(bool); !ok {
+				return nil, atField("buildInfo", fieldError(key, "must specify a boolean"))
+			}
+		default:
+			err := errors.New(fmt.Sprintf("invalid buildInfo setting %s", key))
+			return nil, atField("buildInfo", atField(key, err))
+		}
+	}
+	return &options, nil
+}
+
+// applyGoBuildInfo returns a copy of the version JSON in which fields that were not given explicitly are filled from
+// the Go build information of the target executable, as selected by the buildInfo field.
+func applyGoBuildInfo(versionJson map[string]interface{}, targetFileName string) (map[string]interface{}, error) {
+	buildInfoObj, ok := versionJson["buildInfo"]
+	if !ok {
+		return versionJson, nil
+	}
+	options, err := parseBuildInfoOptions(buildInfoObj)
+	if err != nil || options == nil {
+		return versionJson, err
+	}
+	if targetFileName == "" {
+		return nil, fieldError("buildInfo", "requires a target executable")
+	}
+	info, err := ReadGoBuildInfo(targetFileName)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("could not read Go build information from '%s' (%s)", targetFileName, err))
+		return nil, atField("buildInfo", err)
+	}
+
+	result := make(map[string]interface{}, len(versionJson))
+	for key, value := range versionJson {
+		result[key] = value
+	}
+	stringFileInfoJson := make(map[string]interface{})
+	if stringFileInfoObj, ok := versionJson["stringFileInfo"]; ok {
+		original, ok := stringFileInfoObj.(map[string]interface{})
+		if !ok {
+			return nil, fieldError("stringFileInfo", "must specify an object")
+		}
+		for key, value := range original {
+			stringFileInfoJson[key] = value
+		}
+	}
+	result["stringFileInfo"] = stringFileInfoJson
+
+	if options.ProductVersion && isTaggedModuleVersion(info.ModuleVersion) {
+		// the semantic version is mapped onto the fixed product version and kept as the product version string
+		if _, ok := result["productVersion"]; !ok {
+			result["productVersion"] = info.ModuleVersion
+		}
+	}
+	if options.RevisionKey != "" && info.Revision != "" {
+		customJson := make(map[string]interface{})
+		if customObj, ok := stringFileInfoJson["custom"]; ok {
+			original, ok := customObj.(map[string]interface{})
+			if !ok {
+				return nil, atField("stringFileInfo", fieldError("custom", "must specify an object"))
+			}
+			for key, value := range original {
+				customJson[key] = value
+			}
+		}
+		if _, ok := customJson[options.RevisionKey]; !ok {
+			customJson[options.RevisionKey] = info.Revision
+		}
+		stringFileInfoJson["custom"] = customJson
+	}
+	if options.PrivateBuild && info.Modified {
+		fileFlags := []interface{}{"VS_FF_PRIVATEBUILD"}
+		if fileFlagsObj, ok := versionJson["fileFlags"]; ok {
+			if value, ok := fileFlagsObj.(int64); ok {
+				result["fileFlags"] = value | VS_FF_PRIVATEBUILD
+			} else if original, ok := fileFlagsObj.([]interface{}); ok {
+				result["fileFlags"] = append(fileFlags, original...)
+			} else {
+				return nil, fieldError("fileFlags", "must specify a list of strings or an integer")
+			}
+		} else {
+			result["fileFlags"] = fileFlags
+		}
+		// Windows expects PrivateBuild to describe the private build whenever VS_FF_PRIVATEBUILD is set
+		if _, ok := stringFileInfoJson["privateBuild"]; !ok {
+			stringFileInfoJson["privateBuild"] = "Built from a modified working tree"
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// VersionStringTable is a table of version strings for one language and code page.
+type VersionStringTable struct {
+	Key     string // the language and code page as eight hexadecimal digits, such as 040904B0
+	Strings []VersionString
+}
+
+// VersionInfo is the content of a version resource, as decoded by DecodeVersionInfo.
+type VersionInfo struct {
+	FixedFileInfo VS_FIXEDFILEINFO
+	StringTables  []VersionStringTable
+	Translations  []uint32 // the language in the low word and the code page in the high word
+}
+
+// Lookup returns the value of a version string from the first string table that has it.
+func (info *VersionInfo) Lookup(key string) (string, bool) {
+	for _, table := range info.StringTables {
+		for _, pair := range table.Strings {
+			if pair.Key == key {
+				return pair.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// versionNode is one of the nested structures of a version resource, which all share the same header.
+type versionNode struct {
+	Key      string
+	Type     uint16
+	Value    []byte
+	Children []*versionNode
+}
+
+func decodeUTF16(data []byte) string {
+	chars := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		char := binary.LittleEndian.Uint16(data[i:])
+		if char == 0 {
+			break
+		}
+		chars = append(chars, char)
+	}
+	return string(utf16.Decode(chars))
+}
+
+// decodeVersionNode decodes the structure at the start of data, which must be aligned on a 32-bit boundary, and
+// returns it with the number of bytes that it occupies.
+func decodeVersionNode(data []byte) (*versionNode, int, error) {
+	if len(data) < 6 {
+		return nil, 0, errors.New("version resource is truncated")
+	}
+	length := int(binary.LittleEndian.Uint16(data))
+	valueLength := int(binary.LittleEndian.Uint16(data[2:]))
+	node := &versionNode{Type: binary.LittleEndian.Uint16(data[4:])}
+	if length < 6 || length > len(data) {
+		return nil, 0, errors.New("version resource is truncated")
+	}
+	data = data[:length]
+	offset := 6
+	for offset+1 < length && binary.LittleEndian.Uint16(data[offset:]) != 0 {
+		offset += 2
+	}
+	node.Key = decodeUTF16(data[6:offset])
+	offset = (offset + 2 + 3) &^ 3
+	if node.Type == 1 {
+		// the length of a text value is counted in characters
+		valueLength *= 2
+	}
+	if offset+valueLength > length {
+		valueLength = length - offset
+	}
+	if valueLength > 0 {
+		node.Value = data[offset : offset+valueLength]
+		offset += valueLength
+	}
+	for offset = (offset + 3) &^ 3; offset < length; offset = (offset + 3) &^ 3 {
+		child, size, err := decodeVersionNode(data[offset:])
+		if err != nil {
+			return nil, 0, err
+		}
+		node.Children = append(node.Children, child)
+		offset += size
+	}
+	return node, length, nil
+}
+
+// DecodeVersionInfo decodes a version resource such as EncodeVersionInfo produces.
+func DecodeVersionInfo(data []byte) (*VersionInfo, error) {
+	root, _, err := decodeVersionNode(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.Key != "VS_VERSION_INFO" {
+		return nil, errors.New(fmt.Sprintf("invalid version resource key %s", root.Key))
+	}
+	info := &VersionInfo{}
+	if len(root.Value) < binary.Size(info.FixedFileInfo) {
+		return nil, errors.New("version resource has no fixed file information")
+	}
+	binary.Read(bytes.NewReader(root.Value), binary.LittleEndian, &info.FixedFileInfo)
+	if info.FixedFileInfo.Signature != VS_FFI_SIGNATURE {
+		return nil, errors.New("invalid fixed file information signature")
+	}
+	for _, child := range root.Children {
+		switch child.Key {
+		case "StringFileInfo":
+			for _, tableNode := range child.Children {
+				table := VersionStringTable{Key: strings.ToUpper(tableNode.Key)}
+				for _, stringNode := range tableNode.Children {
+					table.Strings = append(table.Strings, VersionString{
+						Key:   stringNode.Key,
+						Value: decodeUTF16(stringNode.Value),
+					})
+				}
+				info.StringTables = append(info.StringTables, table)
+			}
+		case "VarFileInfo":
+			for _, varNode := range child.Children {
+				if varNode.Key != "Translation" {
+					continue
+				}
+				for i := 0; i+4 <= len(varNode.Value); i += 4 {
+					info.Translations = append(info.Translations, binary.LittleEndian.Uint32(varNode.Value[i:]))
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+// DecodeMessageTable decodes a message table resource such as EncodeMessageTable produces.  The line break that
+// terminates each message is removed.
+func DecodeMessageTable(data []byte) (map[uint32]string, error) {
+	if len(data) < 4 {
+		return nil, errors.New("message table is truncated")
+	}
+	blockCount := int(binary.LittleEndian.Uint32(data))
+	if 4+12*blockCount > len(data) {
+		return nil, errors.New("message table is truncated")
+	}
+	messages := make(map[uint32]string)
+	for i := 0; i < blockCount; i++ {
+		block := data[4+12*i:]
+		lowId := binary.LittleEndian.Uint32(block)
+		highId := binary.LittleEndian.Uint32(block[4:])
+		offset := int(binary.LittleEndian.Uint32(block[8:]))
+		for id := uint64(lowId); id <= uint64(highId); id++ {
+			if offset+4 > len(data) {
+				return nil, errors.New("message table is truncated")
+			}
+			length := int(binary.LittleEndian.Uint16(data[offset:]))
+			flags := binary.LittleEndian.Uint16(data[offset+2:])
+			if length < 4 || offset+length > len(data) {
+				return nil, errors.New("message table entry is truncated")
+			}
+			text := data[offset+4 : offset+length]
+			var message string
+			if flags&1 != 0 {
+				message = decodeUTF16(text)
+			} else {
+				message = string(bytes.TrimRight(text, "\x00"))
+			}
+			messages[uint32(id)] = strings.TrimSuffix(message, "\r\n")
+			offset += length
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,329 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	DS_SETFONT   = 0x00000040
+	DS_SHELLFONT = 0x00000048
+
+	WS_CHILD   = 0x40000000
+	WS_VISIBLE = 0x10000000
+)
+
+// Window, dialog and control style names accepted in the style field of dialogs and controls.  The control style
+// names overlap in value, so it is up to the caller to use only those that apply to the class of the control.
+var windowStyles = map[string]uint32{
+	"WS_OVERLAPPED":       0x00000000,
+	"WS_POPUP":            0x80000000,
+	"WS_CHILD":            0x40000000,
+	"WS_MINIMIZE":         0x20000000,
+	"WS_VISIBLE":          0x10000000,
+	"WS_DISABLED":         0x08000000,
+	"WS_CLIPSIBLINGS":     0x04000000,
+	"WS_CLIPCHILDREN":     0x02000000,
+	"WS_MAXIMIZE":         0x01000000,
+	"WS_CAPTION":          0x00C00000,
+	"WS_BORDER":           0x00800000,
+	"WS_DLGFRAME":         0x00400000,
+	"WS_VSCROLL":          0x00200000,
+	"WS_HSCROLL":          0x00100000,
+	"WS_SYSMENU":          0x00080000,
+	"WS_THICKFRAME":       0x00040000,
+	"WS_GROUP":            0x00020000,
+	"WS_TABSTOP":          0x00010000,
+	"WS_MINIMIZEBOX":      0x00020000,
+	"WS_MAXIMIZEBOX":      0x00010000,
+	"WS_OVERLAPPEDWINDOW": 0x00CF0000,
+	"WS_POPUPWINDOW":      0x80880000,
+
+	"DS_ABSALIGN":      0x00000001,
+	"DS_SYSMODAL":      0x00000002,
+	"DS_3DLOOK":        0x00000004,
+	"DS_FIXEDSYS":      0x00000008,
+	"DS_NOFAILCREATE":  0x00000010,
+	"DS_LOCALEDIT":     0x00000020,
+	"DS_SETFONT":       0x00000040,
+	"DS_MODALFRAME":    0x00000080,
+	"DS_NOIDLEMSG":     0x00000100,
+	"DS_SETFOREGROUND": 0x00000200,
+	"DS_CONTROL":       0x00000400,
+	"DS_CENTER":        0x00000800,
+	"DS_CENTERMOUSE":   0x00001000,
+	"DS_CONTEXTHELP":   0x00002000,
+	"DS_SHELLFONT":     0x00000048,
+
+	"BS_PUSHBUTTON":      0x00000000,
+	"BS_DEFPUSHBUTTON":   0x00000001,
+	"BS_CHECKBOX":        0x00000002,
+	"BS_AUTOCHECKBOX":    0x00000003,
+	"BS_RADIOBUTTON":     0x00000004,
+	"BS_3STATE":          0x00000005,
+	"BS_AUTO3STATE":      0x00000006,
+	"BS_GROUPBOX":        0x00000007,
+	"BS_USERBUTTON":      0x00000008,
+	"BS_AUTORADIOBUTTON": 0x00000009,
+	"BS_OWNERDRAW":       0x0000000B,
+	"BS_SPLITBUTTON":     0x0000000C,
+	"BS_DEFSPLITBUTTON":  0x0000000D,
+	"BS_COMMANDLINK":     0x0000000E,
+	"BS_DEFCOMMANDLINK":  0x0000000F,
+	"BS_LEFTTEXT":        0x00000020,
+	"BS_TEXT":            0x00000000,
+	"BS_ICON":            0x00000040,
+	"BS_BITMAP":          0x00000080,
+	"BS_LEFT":            0x00000100,
+	"BS_RIGHT":           0x00000200,
+	"BS_CENTER":          0x00000300,
+	"BS_TOP":             0x00000400,
+	"BS_BOTTOM":          0x00000800,
+	"BS_VCENTER":         0x00000C00,
+	"BS_PUSHLIKE":        0x00001000,
+	"BS_MULTILINE":       0x00002000,
+	"BS_NOTIFY":          0x00004000,
+	"BS_FLAT":            0x00008000,
+
+	"ES_LEFT":        0x00000000,
+	"ES_CENTER":      0x00000001,
+	"ES_RIGHT":       0x00000002,
+	"ES_MULTILINE":   0x00000004,
+	"ES_UPPERCASE":   0x00000008,
+	"ES_LOWERCASE":   0x00000010,
+	"ES_PASSWORD":    0x00000020,
+	"ES_AUTOVSCROLL": 0x00000040,
+	"ES_AUTOHSCROLL": 0x00000080,
+	"ES_NOHIDESEL":   0x00000100,
+	"ES_OEMCONVERT":  0x00000400,
+	"ES_READONLY":    0x00000800,
+	"ES_WANTRETURN":  0x00001000,
+	"ES_NUMBER":      0x00002000,
+
+	"SS_LEFT":            0x00000000,
+	"SS_CENTER":          0x00000001,
+	"SS_RIGHT":           0x00000002,
+	"SS_ICON":            0x00000003,
+	"SS_BLACKRECT":       0x00000004,
+	"SS_GRAYRECT":        0x00000005,
+	"SS_WHITERECT":       0x00000006,
+	"SS_BLACKFRAME":      0x00000007,
+	"SS_GRAYFRAME":       0x00000008,
+	"SS_WHITEFRAME":      0x00000009,
+	"SS_SIMPLE":          0x0000000B,
+	"SS_LEFTNOWORDWRAP":  0x0000000C,
+	"SS_OWNERDRAW":       0x0000000D,
+	"SS_BITMAP":          0x0000000E,
+	"SS_ENHMETAFILE":     0x0000000F,
+	"SS_ETCHEDHORZ":      0x00000010,
+	"SS_ETCHEDVERT":      0x00000011,
+	"SS_ETCHEDFRAME":     0x00000012,
+	"SS_REALSIZECONTROL": 0x00000040,
+	"SS_NOPREFIX":        0x00000080,
+	"SS_NOTIFY":          0x00000100,
+	"SS_CENTERIMAGE":     0x00000200,
+	"SS_RIGHTJUST":       0x00000400,
+	"SS_REALSIZEIMAGE":   0x00000800,
+	"SS_SUNKEN":          0x00001000,
+	"SS_EDITCONTROL":     0x00002000,
+	"SS_ENDELLIPSIS":     0x00004000,
+	"SS_PATHELLIPSIS":    0x00008000,
+	"SS_WORDELLIPSIS":    0x0000C000,
+
+	"LBS_NOTIFY":            0x00000001,
+	"LBS_SORT":              0x00000002,
+	"LBS_NOREDRAW":          0x00000004,
+	"LBS_MULTIPLESEL":       0x00000008,
+	"LBS_OWNERDRAWFIXED":    0x00000010,
+	"LBS_OWNERDRAWVARIABLE": 0x00000020,
+	"LBS_HASSTRINGS":        0x00000040,
+	"LBS_USETABSTOPS":       0x00000080,
+	"LBS_NOINTEGRALHEIGHT":  0x00000100,
+	"LBS_MULTICOLUMN":       0x00000200,
+	"LBS_WANTKEYBOARDINPUT": 0x00000400,
+	"LBS_EXTENDEDSEL":       0x00000800,
+	"LBS_DISABLENOSCROLL":   0x00001000,
+	"LBS_NODATA":            0x00002000,
+	"LBS_NOSEL":             0x00004000,
+	"LBS_STANDARD":          0x00A00003,
+
+	"CBS_SIMPLE":            0x00000001,
+	"CBS_DROPDOWN":          0x00000002,
+	"CBS_DROPDOWNLIST":      0x00000003,
+	"CBS_OWNERDRAWFIXED":    0x00000010,
+	"CBS_OWNERDRAWVARIABLE": 0x00000020,
+	"CBS_AUTOHSCROLL":       0x00000040,
+	"CBS_OEMCONVERT":        0x00000080,
+	"CBS_SORT":              0x00000100,
+	"CBS_HASSTRINGS":        0x00000200,
+	"CBS_NOINTEGRALHEIGHT":  0x00000400,
+	"CBS_DISABLENOSCROLL":   0x00000800,
+	"CBS_UPPERCASE":         0x00002000,
+	"CBS_LOWERCASE":         0x00004000,
+
+	"SBS_HORZ":        0x00000000,
+	"SBS_VERT":        0x00000001,
+	"SBS_TOPALIGN":    0x00000002,
+	"SBS_LEFTALIGN":   0x00000002,
+	"SBS_BOTTOMALIGN": 0x00000004,
+	"SBS_RIGHTALIGN":  0x00000004,
+	"SBS_SIZEBOX":     0x00000008,
+	"SBS_SIZEGRIP":    0x00000010,
+}
+
+// Extended window style names accepted in the exStyle field of dialogs and controls.
+var windowExStyles = map[string]uint32{
+	"WS_EX_DLGMODALFRAME":   0x00000001,
+	"WS_EX_NOPARENTNOTIFY":  0x00000004,
+	"WS_EX_TOPMOST":         0x00000008,
+	"WS_EX_ACCEPTFILES":     0x00000010,
+	"WS_EX_TRANSPARENT":     0x00000020,
+	"WS_EX_MDICHILD":        0x00000040,
+	"WS_EX_TOOLWINDOW":      0x00000080,
+	"WS_EX_WINDOWEDGE":      0x00000100,
+	"WS_EX_CLIENTEDGE":      0x00000200,
+	"WS_EX_CONTEXTHELP":     0x00000400,
+	"WS_EX_RIGHT":           0x00001000,
+	"WS_EX_LEFT":            0x00000000,
+	"WS_EX_RTLREADING":      0x00002000,
+	"WS_EX_LEFTSCROLLBAR":   0x00004000,
+	"WS_EX_CONTROLPARENT":   0x00010000,
+	"WS_EX_STATICEDGE":      0x00020000,
+	"WS_EX_APPWINDOW":       0x00040000,
+	"WS_EX_LAYERED":         0x00080000,
+	"WS_EX_NOINHERITLAYOUT": 0x00100000,
+	"WS_EX_LAYOUTRTL":       0x00400000,
+	"WS_EX_COMPOSITED":      0x02000000,
+	"WS_EX_NOACTIVATE":      0x08000000,
+}
+
+// Predefined window classes that are encoded as ordinals in dialog templates.
+var dialogClassOrdinals = map[string]uint16{
+	"BUTTON":    0x0080,
+	"EDIT":      0x0081,
+	"STATIC":    0x0082,
+	"LISTBOX":   0x0083,
+	"SCROLLBAR": 0x0084,
+	"COMBOBOX":  0x0085,
+}
+
+type DialogRect struct {
+	X  int16
+	Y  int16
+	CX int16
+	CY int16
+}
+
+type DialogFont struct {
+	PointSize uint16
+	Weight    uint16
+	Italic    bool
+	Charset   uint8
+	Typeface  string
+}
+
+type DialogControl struct {
+	HelpId  uint32
+	ExStyle uint32
+	Style   uint32
+	Rect    DialogRect
+	Id      uint32
+	Class   NameOrOrdinal
+	Text    NameOrOrdinal
+}
+
+type Dialog struct {
+	HelpId   uint32
+	ExStyle  uint32
+	Style    uint32
+	Rect     DialogRect
+	Menu     NameOrOrdinal
+	Class    NameOrOrdinal
+	Caption  string
+	Font     *DialogFont
+	Controls []DialogControl
+}
+
+// The following structures define the fixed-size portions of the DLGTEMPLATEEX and DLGITEMTEMPLATEEX formats.
+// They are documented on MSDN but not included in any Win32 header file because of their variable size.
+
+type dlgTemplateEx struct {
+	DlgVer    uint16
+	Signature uint16
+	HelpId    uint32
+	ExStyle   uint32
+	Style     uint32
+	CDlgItems uint16
+	Rect      DialogRect
+}
+
+type dlgItemTemplateEx struct {
+	HelpId  uint32
+	ExStyle uint32
+	Style   uint32
+	Rect    DialogRect
+	Id      uint32
+}
+
+func EncodeDialog(dialog *Dialog) []byte {
+	var buf bytes.Buffer
+	style := dialog.Style
+	if dialog.Font != nil {
+		style |= DS_SETFONT
+	}
+	binary.Write(&buf, binary.LittleEndian, &dlgTemplateEx{
+		DlgVer:    1,
+		Signature: 0xFFFF,
+		HelpId:    dialog.HelpId,
+		ExStyle:   dialog.ExStyle,
+		Style:     style,
+		CDlgItems: uint16(len(dialog.Controls)),
+		Rect:      dialog.Rect,
+	})
+	writeNameOrOrdinal(&buf, dialog.Menu)
+	writeNameOrOrdinal(&buf, dialog.Class)
+	writeUTF16String(&buf, dialog.Caption)
+	if dialog.Font != nil {
+		binary.Write(&buf, binary.LittleEndian, dialog.Font.PointSize)
+		binary.Write(&buf, binary.LittleEndian, dialog.Font.Weight)
+		if dialog.Font.Italic {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		buf.WriteByte(dialog.Font.Charset)
+		writeUTF16String(&buf, dialog.Font.Typeface)
+	}
+	for _, control := range dialog.Controls {
+		// each item template must begin on a DWORD boundary
+		alignBuffer(&buf, 4)
+		binary.Write(&buf, binary.LittleEndian, &dlgItemTemplateEx{
+			HelpId:  control.HelpId,
+			ExStyle: control.ExStyle,
+			Style:   control.Style,
+			Rect:    control.Rect,
+			Id:      control.Id,
+		})
+		writeNameOrOrdinal(&buf, control.Class)
+		writeNameOrOrdinal(&buf, control.Text)
+		// no creation data
+		binary.Write(&buf, binary.LittleEndian, uint16(0))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// hexData decodes bytes written as hexadecimal pairs, which may be separated by spaces and "|" to show the fields of a
+// structure.
+func hexData(t *testing.T, text string) []byte {
+	data, err := hex.DecodeString(strings.NewReplacer(" ", "", "|", "", "\n", "", "\t", "").Replace(text))
+	if err != nil {
+		t.Fatalf("invalid test data %q: %s", text, err)
+	}
+	return data
+}
+
+// parseTestResources parses a resource file given as JSON with comments and returns the data of its resources of the
+// given type, in order.
+func parseTestResources(t *testing.T, text string, resourceType ResourceType) ([][]byte, error) {
+	jsonData, _, err := DecodeJSONC([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := ParseResources(jsonData, "", "")
+	if err != nil {
+		return nil, err
+	}
+	var data [][]byte
+	for _, res := range set.Resources {
+		if res.Type == resourceType {
+			data = append(data, res.Data)
+		}
+	}
+	return data, nil
+}
+
+func TestEncodeDialog(t *testing.T) {
+	for _, test := range []struct {
+		Name string
+		Json string
+		Data string
+	}{
+		{
+			Name: "no font or controls",
+			Json: `{"id": 1, "style": ["WS_POPUP", "WS_CAPTION"], "rect": [10, 20, 100, 50], "caption": "A"}`,
+			Data: `0100 FFFF | 00000000 | 00000000 | 0000C080 | 0000 | 0A00 1400 6400 3200
+				0000 | 0000 | 4100 0000`,
+		},
+		{
+			// the font ends on a WORD boundary, so the first control is padded to a DWORD boundary, as is the second
+			Name: "font and controls",
+			Json: `{"id": 1, "style": ["WS_POPUP"], "rect": [0, 0, 10, 10], "menu": 5,
+				"font": {"typeface": "AB", "pointSize": 9},
+				"controls": [
+					{"class": "BUTTON", "text": "O", "id": 100, "rect": [1, 2, 3, 4]},
+					{"class": "X", "id": 2, "rect": [0, 0, 1, 1], "style": ["WS_TABSTOP"], "helpId": 7}
+				]}`,
+			Data: `0100 FFFF | 00000000 | 00000000 | 40000080 | 0200 | 0000 0000 0A00 0A00
+				FFFF 0500 | 0000 | 0000 | 0900 9001 00 01 4100 4200 0000 | 0000
+				00000000 | 00000000 | 00000050 | 0100 0200 0300 0400 | 64000000 | FFFF 8000 | 4F00 0000 | 0000 | 0000
+				07000000 | 00000000 | 00000150 | 0000 0000 0100 0100 | 02000000 | 5800 0000 | 0000 | 0000`,
+		},
+		{
+			Name: "extended styles and named class",
+			Json: `{"id": 1, "style": 0, "exStyle": 8, "rect": [-1, -1, 1, 1], "class": "MyDialog", "helpId": 258}`,
+			Data: `0100 FFFF | 02010000 | 08000000 | 00000000 | 0000 | FFFF FFFF 0100 0100
+				0000 | 4D00 7900 4400 6900 6100 6C00 6F00 6700 0000 | 0000`,
+		},
+	} {
+		data, err := parseTestResources(t, `{"dialogs": [`+test.Json+`]}`, ResourceTypeDialog)
+		if err != nil {
+			t.Errorf("%s: %s", test.Name, err)
+		} else if expected := hexData(t, test.Data); len(data) != 1 || !bytes.Equal(data[0], expected) {
+			t.Errorf("%s: got % X, expected % X", test.Name, data, expected)
+		}
+	}
+}
+
+func TestParseDialogErrors(t *testing.T) {
+	for _, test := range []struct {
+		Json  string
+		Paths []string
+	}{
+		{`{"id": 1, "rect": [0, 0, 1]}`, []string{"/dialogs/0/rect"}},
+		{`{"id": 1, "rect": [0, 0, 1, 1], "style": ["DS_SETFONT"]}`, []string{"/dialogs/0/font"}},
+		{`{"id": 1, "rect": [0, 0, 1, 1], "style": ["WS_POPUP", "WS_BOGUS"]}`, []string{"/dialogs/0/style/1"}},
+		{`{"id": 1, "rect": [0, 0, 1, 1], "controls": [{"rect": [0, 40000, 1, 1]}]}`,
+			[]string{"/dialogs/0/controls/0/class", "/dialogs/0/controls/0/rect/1"}},
+		{`{"id": 1, "rect": [0, 0, 1, 1], "font": {"weight": 1001}}`,
+			[]string{"/dialogs/0/font/typeface", "/dialogs/0/font/weight"}},
+	} {
+		_, err := parseTestResources(t, `{"dialogs": [`+test.Json+`]}`, ResourceTypeDialog)
+		if err == nil {
+			t.Errorf("%s: no error", test.Json)
+		} else if paths := errorPaths(err); !reflect.DeepEqual(paths, test.Paths) {
+			t.Errorf("%s: errors at %q, expected %q (%s)", test.Json, paths, test.Paths, err)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of ResourceChange.
+const (
+	ResourceAdded   = "added"
+	ResourceRemoved = "removed"
+	ResourceChanged = "changed"
+)
+
+// FieldChange is a difference in one decoded field of a resource.  Old or New is nil if the field is only present on
+// one side.
+type FieldChange struct {
+	Field string  `json:"field"`
+	Old   *string `json:"old"`
+	New   *string `json:"new"`
+}
+
+// ResourceChange describes how one resource, identified by type, name or ID and language, differs between two sets
+// of resources.
+type ResourceChange struct {
+	Resource string        `json:"resource"`
+	Change   string        `json:"change"`
+	Fields   []FieldChange `json:"fields,omitempty"`
+}
+
+// fieldDiff collects the changes between two sets of named field values.
+type fieldDiff []FieldChange
+
+func (diff *fieldDiff) compare(field string, oldValue *string, newValue *string) {
+	if oldValue == nil && newValue == nil || oldValue != nil && newValue != nil && *oldValue == *newValue {
+		return
+	}
+	*diff = append(*diff, FieldChange{Field: field, Old: oldValue, New: newValue})
+}
+
+func (diff *fieldDiff) compareMaps(oldValues map[string]string, newValues map[string]string) {
+	var fields []string
+	for field := range oldValues {
+		fields = append(fields, field)
+	}
+	for field := range newValues {
+		if _, ok := oldValues[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		var oldValue, newValue *string
+		if value, ok := oldValues[field]; ok {
+			oldValue = &value
+		}
+		if value, ok := newValues[field]; ok {
+			newValue = &value
+		}
+		diff.compare(field, oldValue, newValue)
+	}
+}
+
+func formatFileVersion(ms uint32, ls uint32) string {
+	return fmt.Sprintf("%d.%d.%d.%d", ms>>16, ms&0xFFFF, ls>>16, ls&0xFFFF)
+}
+
+func formatFlags(names []namedValue, flags uint32) string {
+	var parts []string
+	for _, named := range names {
+		if flags&named.Value != 0 {
+			parts = append(parts, named.Name)
+			flags &^= named.Value
+		}
+	}
+	if flags != 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("0x%08X", flags))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// versionFields returns the fields of a version resource by the names they have in a resource file, with the version
+// strings under StringFileInfo/<language and code page>/<key>.
+func versionFields(info *VersionInfo) map[string]string {
+	fixed := &info.FixedFileInfo
+	fields := map[string]string{
+		"strucVersion":   fmt.Sprintf("0x%08X", fixed.StrucVersion),
+		"fileVersion":    formatFileVersion(fixed.FileVersionMS, fixed.FileVersionLS),
+		"productVersion": formatFileVersion(fixed.ProductVersionMS, fixed.ProductVersionLS),
+		"fileFlagsMask":  formatFlags(fileFlagNames, fixed.FileFlagsMask),
+		"fileFlags":      formatFlags(fileFlagNames, fixed.FileFlags),
+		"fileOS":         lookupValueName(fileOSNames, fixed.FileOS),
+		"fileType":       lookupValueName(fileTypeNames, fixed.FileType),
+		"fileSubtype":    fmt.Sprintf("%d", fixed.FileSubtype),
+		"fileDate":       fmt.Sprintf("0x%08X%08X", fixed.FileDateMS, fixed.FileDateLS),
+	}
+	for _, table := range info.StringTables {
+		for _, pair := range table.Strings {
+			fields[fmt.Sprintf("StringFileInfo/%s/%s", table.Key, pair.Key)] = pair.Value
+		}
+	}
+	var translations []string
+	for _, translation := range info.Translations {
+		translations = append(translations, fmt.Sprintf("%04X%04X", translation&0xFFFF, translation>>16))
+	}
+	fields["VarFileInfo/Translation"] = strings.Join(translations, ", ")
+	return fields
+}
+
+func messageFields(messages map[uint32]string) map[string]string {
+	fields := make(map[string]string, len(messages))
+	for id, text := range messages {
+		fields[fmt.Sprintf("message %d", id)] = text
+	}
+	return fields
+}
+
+// decodedFields decodes the resources that have a known structure into named fields, or returns false for the others
+// and for data that cannot be decoded.
+func decodedFields(resourceType ResourceType, data []byte) (map[string]string, bool) {
+	switch resourceType {
+	case ResourceTypeVersion:
+		if info, err := DecodeVersionInfo(data); err == nil {
+			return versionFields(info), true
+		}
+	case ResourceTypeMessageTable:
+		if messages, err := DecodeMessageTable(data); err == nil {
+			return messageFields(messages), true
+		}
+	case ResourceTypeManifest:
+		return map[string]string{"manifest": string(data)}, true
+	}
+	return nil, false
+}
+
+// compareResourceData returns the field-level differences between two versions of a resource.  Resources whose
+// structure is not known, or whose differences do not show in the decoded fields, are reported by size.
+func compareResourceData(resourceType ResourceType, oldData []byte, newData []byte) []FieldChange {
+	var diff fieldDiff
+	oldFields, oldOk := decodedFields(resourceType, oldData)
+	newFields, newOk := decodedFields(resourceType, newData)
+	if oldOk && newOk {
+		diff.compareMaps(oldFields, newFields)
+	}
+	if len(diff) == 0 {
+		// the data differs even if the sizes are the same
+		oldSize, newSize := fmt.Sprintf("%d bytes", len(oldData)), fmt.Sprintf("%d bytes", len(newData))
+		diff = fieldDiff{{Field: "data", Old: &oldSize, New: &newSize}}
+	}
+	return diff
+}
+
+func findResource(resources []*ExecutableResource, res *ExecutableResource) int {
+	for i, other := range resources {
+		if sameResource(res, other) && res.Language == other.Language {
+			return i
+		}
+	}
+	return -1
+}
+
+// DiffResources compares two lists of resources, such as those in an executable before and after an update, and
+// returns the resources that were added, removed or changed in the order of the new list followed by the removed
+// resources.
+func DiffResources(oldResources []*ExecutableResource, newResources []*ExecutableResource) []ResourceChange {
+	var changes []ResourceChange
+	for _, res := range newResources {
+		i := findResource(oldResources, res)
+		if i < 0 {
+			changes = append(changes, ResourceChange{Resource: res.String(), Change: ResourceAdded})
+		} else if !bytes.Equal(oldResources[i].Data, res.Data) {
+			changes = append(changes, ResourceChange{
+				Resource: res.String(),
+				Change:   ResourceChanged,
+				Fields:   compareResourceData(res.Type, oldResources[i].Data, res.Data),
+			})
+		}
+	}
+	for _, res := range oldResources {
+		if findResource(newResources, res) < 0 {
+			changes = append(changes, ResourceChange{Resource: res.String(), Change: ResourceRemoved})
+		}
+	}
+	return changes
+}
+
+// DiffExecutable works out the changes that WriteExecutable would make to the resources of an executable with the
+// same options, without changing it.
+func (set *ResourceSet) DiffExecutable(fileName string, options *WriteOptions) ([]ResourceChange, error) {
+	if options == nil {
+		options = &WriteOptions{}
+	}
+	existing, err := ReadExecutableResources(fileName)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("failed to read resources of executable file: %s (%s)", fileName, err))
+	}
+	plan, err := set.planUpdate(existing, options)
+	if err != nil {
+		return nil, err
+	}
+	var result []*ExecutableResource
+	if !options.Discard {
+		for _, res := range existing {
+			if findResource(plan.Remove, res) < 0 {
+				result = append(result, res)
+			}
+		}
+	}
+	for _, res := range plan.Write {
+		newRes := set.executableResource(res)
+		if i := findResource(result, newRes); i >= 0 {
+			result[i] = newRes
+		} else {
+			result = append(result, newRes)
+		}
+	}
+	return DiffResources(existing, result), nil
+}
+
+// Kinds of ResourceChange reported by VerifyExecutable.
+const (
+	ResourceMissing    = "missing"
+	ResourceDifferent  = "different"
+	ResourceUnexpected = "unexpected"
+)
+
+// VerifyOptions controls how strictly VerifyExecutable checks an executable.
+type VerifyOptions struct {
+	// Strict reports every resource in the executable that the set does not describe as unexpected, rather than only
+	// the copies of its resources in other languages.
+	Strict bool
+}
+
+// VerifyExecutable checks that an executable contains the resources in the set.  A resource that is not in the
+// executable is reported as missing, and one whose data differs as different, with the fields of the executable's
+// copy as Old and those expected as New.  A resource with the same type and name or ID in another language is
+// reported as unexpected, since Windows may load it instead.  Resources of other types and IDs are only checked in
+// strict mode, where they are reported as unexpected too.
+func (set *ResourceSet) VerifyExecutable(fileName string, options *VerifyOptions) ([]ResourceChange, error) {
+	if options == nil {
+		options = &VerifyOptions{}
+	}
+	existing, err := ReadExecutableResources(fileName)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("failed to read resources of executable file: %s (%s)", fileName, err))
+	}
+	var changes []ResourceChange
+	for _, res := range set.Resources {
+		expected := set.executableResource(res)
+		if i := findResource(existing, expected); i < 0 {
+			changes = append(changes, ResourceChange{Resource: expected.String(), Change: ResourceMissing})
+		} else if !bytes.Equal(existing[i].Data, expected.Data) {
+			changes = append(changes, ResourceChange{
+				Resource: expected.String(),
+				Change:   ResourceDifferent,
+				Fields:   compareResourceData(res.Type, existing[i].Data, expected.Data),
+			})
+		}
+		for _, other := range existing {
+			if sameResource(expected, other) && other.Language != expected.Language {
+				changes = append(changes, ResourceChange{Resource: other.String(), Change: ResourceUnexpected})
+			}
+		}
+	}
+	if options.Strict {
+		for _, other := range existing {
+			described := false
+			for _, res := range set.Resources {
+				described = described || sameResource(set.executableResource(res), other)
+			}
+			if !described {
+				changes = append(changes, ResourceChange{Resource: other.String(), Change: ResourceUnexpected})
+			}
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyExecutableStrict(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "hello.exe")
+	if err := ioutil.WriteFile(fileName, buildTestExecutable(t, testResources(), 0, 0), 0666); err != nil {
+		t.Fatal(err)
+	}
+	// the set describes all but the last of the resources in the executable
+	set := &ResourceSet{Language: 0x0409, Resources: testResources()[:2]}
+	changes, err := set.VerifyExecutable(fileName, nil)
+	if err != nil {
+		t.Fatalf("VerifyExecutable failed: %s", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("VerifyExecutable reported %v", changes)
+	}
+	changes, err = set.VerifyExecutable(fileName, &VerifyOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("VerifyExecutable failed: %s", err)
+	}
+	expected := (&ExecutableResource{Type: ResourceTypeRCData, Name: "CONFIG", Language: 0x0409}).String()
+	if len(changes) != 1 || changes[0].Resource != expected || changes[0].Change != ResourceUnexpected {
+		t.Errorf("VerifyExecutable reported %v in strict mode, expected %s to be unexpected", changes, expected)
+	}
+}
+
+// TestDiffExecutable checks that a dry run reports the decoded fields that change in version resources and message
+// tables, and that the resources the update would delete are reported as removed only with Discard.
+func TestDiffExecutable(t *testing.T) {
+	parse := func(text string) *ResourceSet {
+		jsonData, _, err := DecodeJSONC([]byte(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		set, err := ParseResources(jsonData, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return set
+	}
+	oldSet := parse(`{
+		"language": "en-US",
+		"version": {"fileVersion": "1.0", "stringFileInfo": {"productName": "Hello"}},
+		"messageTable": [{"id": 1, "severity": "Success", "messageText": "Hello"}]
+	}`)
+	resources := append(oldSet.Resources, &Resource{Type: ResourceTypeRCData, Name: "CONFIG", Data: []byte("config")})
+	fileName := filepath.Join(t.TempDir(), "hello.exe")
+	if err := ioutil.WriteFile(fileName, buildTestExecutable(t, resources, 0, 0), 0666); err != nil {
+		t.Fatal(err)
+	}
+	set := parse(`{
+		"language": "en-US",
+		"version": {"fileVersion": "1.2", "stringFileInfo": {"productName": "Hello", "comments": "new"}},
+		"messageTable": [
+			{"id": 1, "severity": "Success", "messageText": "Hello, world"},
+			{"id": 2, "severity": "Success", "messageText": "Goodbye"}
+		]
+	}`)
+	resourceName := func(resourceType ResourceType, name string) string {
+		res := &ExecutableResource{Type: resourceType, Id: 1, Name: name, Language: 0x0409}
+		return res.String()
+	}
+	versionName := resourceName(ResourceTypeVersion, "")
+	messagesName := resourceName(ResourceTypeMessageTable, "")
+	configName := resourceName(ResourceTypeRCData, "CONFIG")
+	for _, discard := range []bool{false, true} {
+		changes, err := set.DiffExecutable(fileName, &WriteOptions{Discard: discard})
+		if err != nil {
+			t.Fatalf("DiffExecutable failed: %s", err)
+		}
+		expected := map[string]string{versionName: ResourceChanged, messagesName: ResourceChanged}
+		if discard {
+			expected[configName] = ResourceRemoved
+		}
+		fields := make(map[string]string)
+		for _, change := range changes {
+			if expected[change.Resource] != change.Change {
+				t.Errorf("discard %v: %s was reported %s, expected %q", discard, change.Resource, change.Change,
+					expected[change.Resource])
+			}
+			delete(expected, change.Resource)
+			for _, field := range change.Fields {
+				fields[change.Resource+" "+field.Field] = fmt.Sprintf("%s -> %s", formatTestValue(field.Old),
+					formatTestValue(field.New))
+			}
+		}
+		if len(expected) > 0 {
+			t.Errorf("discard %v: changes to %v were not reported", discard, expected)
+		}
+		for field, change := range map[string]string{
+			versionName + " fileVersion":                      "1.0.0.0 -> 1.2.0.0",
+			versionName + " StringFileInfo/040904B0/Comments": "(none) -> new",
+			messagesName + " message 1":                       "Hello -> Hello, world",
+			messagesName + " message 2":                       "(none) -> Goodbye",
+		} {
+			if fields[field] != change {
+				t.Errorf("discard %v: %s changed %q, expected %q", discard, field, fields[field], change)
+			}
+		}
+		if _, ok := fields[versionName+" StringFileInfo/040904B0/ProductName"]; ok {
+			t.Errorf("discard %v: the unchanged product name was reported", discard)
+		}
+	}
+}
+
+func formatTestValue(value *string) string {
+	if value == nil {
+		return "(none)"
+	}
+	return *value
+}
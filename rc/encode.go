@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// NameOrOrdinal identifies a class, menu or other item in a resource template either by name or by a 16-bit ordinal.
+// An empty name with an ordinal of zero means that no value is present.
+type NameOrOrdinal struct {
+	Name    string
+	Ordinal uint16
+}
+
+// moveMemory copies size bytes from src to dst, like RtlMoveMemory, to lay out the fixed-size structures of a resource.
+func moveMemory(dst *byte, src *byte, size uintptr) {
+	copy(unsafe.Slice(dst, size), unsafe.Slice(src, size))
+}
+
+// putUTF16String writes text to data as little-endian UTF-16 followed by a zero, stopping when data is full.
+func putUTF16String(data []byte, text string) {
+	for i, c := range append(utf16.Encode([]rune(text)), 0) {
+		if 2*i+2 > len(data) {
+			break
+		}
+		binary.LittleEndian.PutUint16(data[2*i:], c)
+	}
+}
+
+func writeUTF16String(buf *bytes.Buffer, text string) {
+	for _, c := range utf16.Encode([]rune(text)) {
+		binary.Write(buf, binary.LittleEndian, c)
+	}
+	binary.Write(buf, binary.LittleEndian, uint16(0))
+}
+
+func writeNameOrOrdinal(buf *bytes.Buffer, value NameOrOrdinal) {
+	if value.Name != "" {
+		writeUTF16String(buf, value.Name)
+	} else if value.Ordinal != 0 {
+		binary.Write(buf, binary.LittleEndian, uint16(0xFFFF))
+		binary.Write(buf, binary.LittleEndian, value.Ordinal)
+	} else {
+		binary.Write(buf, binary.LittleEndian, uint16(0))
+	}
+}
+
+// alignBuffer pads the buffer with zero bytes until its length is a multiple of the given alignment.
+func alignBuffer(buf *bytes.Buffer, alignment int) {
+	for buf.Len()%alignment != 0 {
+		buf.WriteByte(0)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WriteOptions controls how WriteExecutable updates an executable.
+type WriteOptions struct {
+	// Discard deletes the resources that the executable already contains before the new ones are added.
+	Discard bool
+
+	// Timestamp is the value to which the timestamps in the resource directory are set.
+	Timestamp uint32
+
+	// OutputFileName names the file to which the updated executable is written.  If it is empty, the executable is
+	// replaced.
+	OutputFileName string
+
+	// BackupSuffix, if not empty, is appended to the name of the output file to give the name of a copy of it that is
+	// made before it is replaced.  No copy is made if the output file does not exist yet.
+	BackupSuffix string
+
+	// Remove selects resources already in the executable that are deleted before the new resources are added.
+	Remove []ResourceSelector
+
+	// Policies decide what happens to a new resource when the executable already has one with the same type and name
+	// or ID, in any language.  The last rule that matches the new resource applies, and PolicyReplace applies when none
+	// does.
+	Policies []PolicyRule
+}
+
+// resourceUpdate lists the changes that WriteExecutable makes to the resources of an executable.
+type resourceUpdate struct {
+	Remove []*ExecutableResource
+	Write  []*Resource
+}
+
+func (set *ResourceSet) executableResource(res *Resource) *ExecutableResource {
+	return &ExecutableResource{Type: res.Type, Id: res.Id, Name: res.Name, Language: set.Language, Data: res.Data}
+}
+
+// planUpdate works out which of the resources already in an executable are removed and which of the new resources
+// are written, following the options.  Conflicts with the fail-on-conflict policy are returned as errors.
+func (set *ResourceSet) planUpdate(existing []*ExecutableResource, options *WriteOptions) (*resourceUpdate, error) {
+	update := &resourceUpdate{}
+	var kept []*ExecutableResource
+	if !options.Discard {
+		for _, res := range existing {
+			removed := false
+			for _, sel := range options.Remove {
+				removed = removed || sel.Matches(res)
+			}
+			if removed {
+				update.Remove = append(update.Remove, res)
+			} else {
+				kept = append(kept, res)
+			}
+		}
+	}
+	var errs ErrorList
+	for _, res := range set.Resources {
+		newRes := set.executableResource(res)
+		// the copies of the resource in every language conflict with it
+		var conflicts []*ExecutableResource
+		for _, other := range kept {
+			if sameResource(newRes, other) {
+				conflicts = append(conflicts, other)
+			}
+		}
+		if len(conflicts) == 0 {
+			update.Write = append(update.Write, res)
+			continue
+		}
+		conflict := conflicts[0]
+		switch policyFor(options.Policies, newRes) {
+		case PolicyReplace:
+			update.Remove = append(update.Remove, conflicts...)
+			update.Write = append(update.Write, res)
+		case PolicyFailOnConflict:
+			errs.add(errors.New(fmt.Sprintf("resource %s conflicts with %s in the executable", newRes, conflict)))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return update, nil
+}
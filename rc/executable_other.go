@@ -0,0 +1,29 @@
+//go:build !windows
+
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+)
+
+// WriteExecutable adds the resources to an executable or DLL.  It relies on the resource update functions of
+// Windows, so on other systems it always fails; write a .res or .syso file instead.
+func (set *ResourceSet) WriteExecutable(fileName string, options *WriteOptions) error {
+	return errors.New("writing resources to an executable is only supported on Windows")
+}
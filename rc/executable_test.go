@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanUpdate(t *testing.T) {
+	existing := []*ExecutableResource{
+		{Type: ResourceTypeVersion, Id: 1, Language: 0x0409},
+		{Type: ResourceTypeManifest, Id: 1, Language: 0x0407},
+		{Type: ResourceTypeRCData, Name: "CONFIG", Language: 0x0409},
+	}
+	set := &ResourceSet{Language: 0x0409, Resources: []*Resource{
+		{Type: ResourceTypeVersion, Id: 1},
+		{Type: ResourceTypeManifest, Id: 1},
+		{Type: ResourceTypeMenu, Id: 100},
+	}}
+	tests := []struct {
+		Policies []string
+		Remove   []string
+		Write    []string
+		Fails    bool
+	}{
+		{
+			// the version conflicts in the same language and the manifest in another one
+			Policies: nil,
+			Remove:   []string{"RT_VERSION/1/0x0409", "RT_MANIFEST/1/0x0407"},
+			Write:    []string{"RT_VERSION/1/0x0409", "RT_MANIFEST/1/0x0409", "RT_MENU/100/0x0409"},
+		},
+		{
+			Policies: []string{"keep-existing"},
+			Write:    []string{"RT_MENU/100/0x0409"},
+		},
+		{
+			Policies: []string{"keep-existing", "RT_MANIFEST=replace"},
+			Remove:   []string{"RT_MANIFEST/1/0x0407"},
+			Write:    []string{"RT_MANIFEST/1/0x0409", "RT_MENU/100/0x0409"},
+		},
+		{
+			Policies: []string{"RT_VERSION=fail-on-conflict"},
+			Fails:    true,
+		},
+		{
+			Policies: []string{"RT_MANIFEST=fail-on-conflict"},
+			Fails:    true,
+		},
+		{
+			Policies: []string{"RT_MENU=fail-on-conflict"},
+			Remove:   []string{"RT_VERSION/1/0x0409", "RT_MANIFEST/1/0x0407"},
+			Write:    []string{"RT_VERSION/1/0x0409", "RT_MANIFEST/1/0x0409", "RT_MENU/100/0x0409"},
+		},
+	}
+	for _, test := range tests {
+		options := &WriteOptions{}
+		for _, text := range test.Policies {
+			rule, err := ParsePolicyRule(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			options.Policies = append(options.Policies, rule)
+		}
+		update, err := set.planUpdate(existing, options)
+		if test.Fails {
+			if err == nil {
+				t.Errorf("%q: the conflict was not reported", test.Policies)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", test.Policies, err)
+			continue
+		}
+		var remove, write []string
+		for _, res := range update.Remove {
+			remove = append(remove, res.String())
+		}
+		for _, res := range update.Write {
+			write = append(write, set.executableResource(res).String())
+		}
+		if !reflect.DeepEqual(remove, test.Remove) || !reflect.DeepEqual(write, test.Write) {
+			t.Errorf("%q: removes %q and writes %q, expected %q and %q", test.Policies, remove, write, test.Remove,
+				test.Write)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"github.com/winlabs/gowin32"
+
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ResourceId returns the name or ID of the resource in the form taken by the Windows resource functions.
+func (res *Resource) ResourceId() gowin32.ResourceId {
+	if res.Name != "" {
+		return gowin32.StringResourceId(res.Name)
+	}
+	return gowin32.IntResourceId(res.Id)
+}
+
+// readUpdate reads the resources of an executable and plans the update, which only requires the resources if some
+// are removed or a policy other than replace may apply.
+func (set *ResourceSet) readUpdate(fileName string, options *WriteOptions) (*resourceUpdate, error) {
+	var existing []*ExecutableResource
+	if len(options.Remove) > 0 || len(options.Policies) > 0 {
+		var err error
+		if existing, err = ReadExecutableResources(fileName); err != nil {
+			return nil, errors.New(fmt.Sprintf("failed to read resources of executable file: %s (%s)", fileName, err))
+		}
+	}
+	return set.planUpdate(existing, options)
+}
+
+// copyFile copies the contents of the file src to the file dst, which is created with the given permissions if it
+// does not exist.
+func copyFile(dst string, src string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// updateResources adds the resources to an executable in place, which may leave it damaged if the update fails.
+// Errors are reported for the file named by displayName, of which the executable is a copy.
+func (set *ResourceSet) updateResources(
+	fileName string,
+	displayName string,
+	plan *resourceUpdate,
+	discard bool) error {
+	update, err := gowin32.NewResourceUpdate(fileName, discard)
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to open executable file for resource update: %s (%s)", displayName, err))
+	}
+	defer update.Close()
+	for _, res := range plan.Remove {
+		resourceType := gowin32.ResourceType(res.Type)
+		if res.TypeName != "" {
+			resourceType = gowin32.CustomResourceType(res.TypeName)
+		}
+		resourceId := gowin32.IntResourceId(res.Id)
+		if res.Name != "" {
+			resourceId = gowin32.StringResourceId(res.Name)
+		}
+		if err := update.Delete(resourceType, resourceId, gowin32.Language(res.Language)); err != nil {
+			return errors.New(fmt.Sprintf("failed to remove resource %s (%s)", res, err))
+		}
+	}
+	for _, res := range plan.Write {
+		err := update.Update(gowin32.ResourceType(res.Type), res.ResourceId(), gowin32.Language(set.Language), res.Data)
+		if err != nil {
+			return errors.New(fmt.Sprintf("failed to update resource (%s)", err))
+		}
+	}
+	if err := update.Save(); err != nil {
+		return errors.New(fmt.Sprintf("failed to save updated resources to executable file: %s (%s)", displayName, err))
+	}
+	return nil
+}
+
+// WriteExecutable adds the resources to an executable or DLL, after removing any resources selected by the options.
+// Conflicts with resources already in the executable are resolved by the policies in the options.  The update is made
+// to a temporary copy in the directory of the output file, which then replaces the output file, so that the executable
+// and the output file are left untouched if any step fails.
+func (set *ResourceSet) WriteExecutable(fileName string, options *WriteOptions) error {
+	if options == nil {
+		options = &WriteOptions{}
+	}
+	outputFileName := options.OutputFileName
+	if outputFileName == "" {
+		outputFileName = fileName
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to open executable file: %s (%s)", fileName, err))
+	}
+	plan, err := set.readUpdate(fileName, options)
+	if err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempFile(filepath.Dir(outputFileName), "."+filepath.Base(outputFileName)+".tmp")
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to create temporary file for executable: %s (%s)", outputFileName, err))
+	}
+	tempFileName := temp.Name()
+	temp.Close()
+	committed := false
+	defer func() {
+		if !committed {
+			os.Remove(tempFileName)
+		}
+	}()
+	if err := copyFile(tempFileName, fileName, info.Mode()); err != nil {
+		return errors.New(fmt.Sprintf("failed to copy executable file: %s (%s)", fileName, err))
+	}
+	if err := os.Chmod(tempFileName, info.Mode()); err != nil {
+		return errors.New(fmt.Sprintf("failed to copy executable file: %s (%s)", fileName, err))
+	}
+	if err := set.updateResources(tempFileName, fileName, plan, options.Discard); err != nil {
+		return err
+	}
+	if err := SetResourceTimestamps(tempFileName, options.Timestamp); err != nil {
+		return errors.New(fmt.Sprintf("failed to set resource timestamps in executable file: %s (%s)", fileName, err))
+	}
+
+	if options.BackupSuffix != "" {
+		if outputInfo, err := os.Stat(outputFileName); err == nil {
+			backupFileName := outputFileName + options.BackupSuffix
+			if err := copyFile(backupFileName, outputFileName, outputInfo.Mode()); err != nil {
+				return errors.New(fmt.Sprintf("failed to back up executable file to %s (%s)", backupFileName, err))
+			}
+		}
+	}
+	if err := os.Rename(tempFileName, outputFileName); err != nil {
+		return errors.New(fmt.Sprintf("failed to replace executable file: %s (%s)", outputFileName, err))
+	}
+	committed = true
+	return nil
+}
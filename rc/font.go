@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// size of the portion of a .fnt header that is copied into a FONTDIRENTRY (dfVersion through dfReserved)
+	fontDirEntryHeaderSize = 113
+
+	fntDeviceOffset = 101
+	fntFaceOffset   = 105
+
+	neResourceTableOffset = 0x24
+	neResourceTypeFont    = 0x8008
+)
+
+// FontDirEntry holds the metadata of a bitmap or vector font that is recorded in an RT_FONTDIR resource.
+type FontDirEntry struct {
+	Ordinal    uint16
+	Header     []byte
+	DeviceName string
+	FaceName   string
+}
+
+func readNullTerminatedString(data []byte, offset uint32) (string, error) {
+	if offset == 0 {
+		return "", nil
+	}
+	if int(offset) >= len(data) {
+		return "", errors.New("string offset lies outside the font file")
+	}
+	end := bytes.IndexByte(data[offset:], 0)
+	if end < 0 {
+		return "", errors.New("string in font file is not null-terminated")
+	}
+	return string(data[offset : int(offset)+end]), nil
+}
+
+// ParseFNT reads the header of a Windows 2.x or 3.x .fnt font and returns the corresponding font directory entry.
+// The ordinal of the returned entry is left as zero.
+func ParseFNT(data []byte) (*FontDirEntry, error) {
+	if len(data) < fontDirEntryHeaderSize+4 {
+		return nil, errors.New("font file is too short")
+	}
+	version := binary.LittleEndian.Uint16(data[0:])
+	if version != 0x0200 && version != 0x0300 {
+		return nil, errors.New(fmt.Sprintf("unsupported .fnt version %04X", version))
+	}
+	deviceName, err := readNullTerminatedString(data, binary.LittleEndian.Uint32(data[fntDeviceOffset:]))
+	if err != nil {
+		return nil, err
+	}
+	faceName, err := readNullTerminatedString(data, binary.LittleEndian.Uint32(data[fntFaceOffset:]))
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, fontDirEntryHeaderSize)
+	copy(header, data)
+	return &FontDirEntry{
+		Header:     header,
+		DeviceName: deviceName,
+		FaceName:   faceName,
+	}, nil
+}
+
+// ExtractFONFonts returns the .fnt fonts contained in the RT_FONT resources of a 16-bit .fon font library.
+func ExtractFONFonts(data []byte) ([][]byte, error) {
+	if len(data) < 0x40 || string(data[0:2]) != "MZ" {
+		return nil, errors.New("font library is not an executable file")
+	}
+	neHeader := binary.LittleEndian.Uint32(data[0x3C:])
+	if uint64(neHeader)+neResourceTableOffset+2 > uint64(len(data)) || string(data[neHeader:neHeader+2]) != "NE" {
+		return nil, errors.New("font library is not a 16-bit executable file")
+	}
+	// offsets are computed in 64 bits so that values read from a malformed file cannot wrap around
+	offset := uint64(neHeader) + uint64(binary.LittleEndian.Uint16(data[neHeader+neResourceTableOffset:]))
+	if offset+2 > uint64(len(data)) {
+		return nil, errors.New("font library resource table is truncated")
+	}
+	alignShift := binary.LittleEndian.Uint16(data[offset:])
+	if alignShift > 16 {
+		return nil, errors.New(fmt.Sprintf("invalid font library resource alignment shift %d", alignShift))
+	}
+	offset += 2
+	fonts := make([][]byte, 0)
+	for {
+		if offset+2 > uint64(len(data)) {
+			return nil, errors.New("font library resource table is truncated")
+		}
+		typeId := binary.LittleEndian.Uint16(data[offset:])
+		if typeId == 0 {
+			break
+		}
+		if offset+8 > uint64(len(data)) {
+			return nil, errors.New("font library resource table is truncated")
+		}
+		count := uint64(binary.LittleEndian.Uint16(data[offset+2:]))
+		offset += 8
+		if offset+12*count > uint64(len(data)) {
+			return nil, errors.New("font library resource table is truncated")
+		}
+		for i := uint64(0); i < count; i++ {
+			nameInfo := offset + 12*i
+			if typeId != neResourceTypeFont {
+				continue
+			}
+			start := uint64(binary.LittleEndian.Uint16(data[nameInfo:])) << alignShift
+			length := uint64(binary.LittleEndian.Uint16(data[nameInfo+2:])) << alignShift
+			if start+length > uint64(len(data)) {
+				return nil, errors.New("font library resource lies outside the file")
+			}
+			font := data[start : start+length]
+			// the resource length is rounded up to the alignment, so trim it to the size recorded in the font
+			if len(font) >= 6 {
+				if size := binary.LittleEndian.Uint32(font[2:]); uint64(size) <= length {
+					font = font[:size]
+				}
+			}
+			fonts = append(fonts, font)
+		}
+		offset += 12 * count
+	}
+	if len(fonts) == 0 {
+		return nil, errors.New("font library does not contain any fonts")
+	}
+	return fonts, nil
+}
+
+// IsOpenTypeFont reports whether the data begins with a TrueType, OpenType or TrueType collection signature.
+func IsOpenTypeFont(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	switch string(data[0:4]) {
+	case "\x00\x01\x00\x00", "OTTO", "true", "ttcf":
+		return true
+	default:
+		return false
+	}
+}
+
+func EncodeFontDir(entries []FontDirEntry) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint16(len(entries)))
+	for _, entry := range entries {
+		binary.Write(&buf, binary.LittleEndian, entry.Ordinal)
+		buf.Write(entry.Header)
+		buf.WriteString(entry.DeviceName)
+		buf.WriteByte(0)
+		buf.WriteString(entry.FaceName)
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
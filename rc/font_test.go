@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// testFNT returns a Windows 3.x .fnt font with the given face name, whose header records its size.
+func testFNT(face string) []byte {
+	data := make([]byte, fontDirEntryHeaderSize+4)
+	binary.LittleEndian.PutUint16(data[0:], 0x0300)
+	copy(data[6:], "Copyright "+face)
+	binary.LittleEndian.PutUint32(data[fntFaceOffset:], uint32(len(data)))
+	data = append(append(data, face...), 0)
+	binary.LittleEndian.PutUint32(data[2:], uint32(len(data)))
+	return data
+}
+
+func appendUint16(data []byte, value uint16) []byte {
+	return append(data, byte(value), byte(value>>8))
+}
+
+// testFON returns a .fon font library that contains the given fonts, preceded by a resource of another type, with
+// resources aligned to 16 bytes.
+func testFON(fonts ...[]byte) []byte {
+	const neHeader = 0x40
+	const resourceTable = 0x80
+	data := make([]byte, 0x100)
+	copy(data, "MZ")
+	binary.LittleEndian.PutUint32(data[0x3C:], neHeader)
+	copy(data[neHeader:], "NE")
+	binary.LittleEndian.PutUint16(data[neHeader+neResourceTableOffset:], resourceTable-neHeader)
+	table := appendUint16(nil, 4)
+	// an RT_FONTDIR resource, which is skipped
+	table = appendUint16(table, 0x8007)
+	table = appendUint16(table, 1)
+	table = append(table, make([]byte, 4+12)...)
+	table = appendUint16(table, neResourceTypeFont)
+	table = appendUint16(table, uint16(len(fonts)))
+	table = append(table, make([]byte, 4)...)
+	var resources []byte
+	for i, font := range fonts {
+		start := len(data) + len(resources)
+		font = append(font, make([]byte, 15-(len(font)+15)%16)...)
+		resources = append(resources, font...)
+		table = appendUint16(table, uint16(start>>4))
+		table = appendUint16(table, uint16(len(font)>>4))
+		table = appendUint16(table, 0)
+		table = appendUint16(table, uint16(0x8001+i))
+		table = append(table, make([]byte, 4)...)
+	}
+	copy(data[resourceTable:], appendUint16(table, 0))
+	return append(data, resources...)
+}
+
+func TestParseFNT(t *testing.T) {
+	font := testFNT("System")
+	entry, err := ParseFNT(font)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(entry.Header, font[:113]) || entry.FaceName != "System" || entry.DeviceName != "" {
+		t.Errorf("got header % X, device %q and face %q", entry.Header, entry.DeviceName, entry.FaceName)
+	}
+
+	oldVersion := append([]byte(nil), font...)
+	binary.LittleEndian.PutUint16(oldVersion, 0x0100)
+	faceOutside := append([]byte(nil), font...)
+	binary.LittleEndian.PutUint32(faceOutside[fntFaceOffset:], uint32(len(font)))
+	unterminated := font[:len(font)-1]
+	for name, data := range map[string][]byte{
+		"short":        font[:116],
+		"version 1":    oldVersion,
+		"face outside": faceOutside,
+		"unterminated": unterminated,
+	} {
+		if _, err := ParseFNT(data); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestExtractFONFonts(t *testing.T) {
+	fonts := [][]byte{testFNT("Small"), testFNT("Large Fonts")}
+	extracted, err := ExtractFONFonts(testFON(fonts...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the padding to the 16-byte alignment is trimmed from each font
+	if !reflect.DeepEqual(extracted, fonts) {
+		t.Errorf("got fonts %q, expected %q", extracted, fonts)
+	}
+
+	corrupt := func(offset int, value uint32, size int) []byte {
+		data := testFON(fonts...)
+		if size == 2 {
+			binary.LittleEndian.PutUint16(data[offset:], uint16(value))
+		} else {
+			binary.LittleEndian.PutUint32(data[offset:], value)
+		}
+		return data
+	}
+	for name, data := range map[string][]byte{
+		"not MZ":                  []byte("PE"),
+		"NE header beyond file":   corrupt(0x3C, 0xFFFFFFF0, 4),
+		"resource table past end": corrupt(0x40+neResourceTableOffset, 0xFFFF, 2),
+		"alignment shift":         corrupt(0x80, 40, 2),
+		"resource count":          corrupt(0x80+2+20+2, 0xFFFF, 2),
+		"font outside file":       corrupt(0x80+2+20+8, 0xFFFF, 2),
+		"no fonts":                corrupt(0x80+2+20, 0, 2),
+	} {
+		if _, err := ExtractFONFonts(data); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestEncodeFontDir(t *testing.T) {
+	dir := t.TempDir()
+	small, large := testFNT("Small"), testFNT("Large")
+	writeTestFiles(t, dir, map[string]string{
+		"small.fnt": string(small),
+		"fonts.fon": string(testFON(small, large)),
+		"font.ttf":  "\x00\x01\x00\x00",
+	})
+	jsonData, _, err := DecodeJSONC([]byte(`{"fonts": [
+		{"id": 1, "file": "small.fnt"},
+		{"id": 10, "file": "fonts.fon"},
+		{"id": 20, "file": "font.ttf"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := ParseResources(jsonData, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fontIds := make([]uint, 0)
+	var fontDir []byte
+	for _, res := range set.Resources {
+		switch res.Type {
+		case ResourceTypeFont:
+			fontIds = append(fontIds, res.Id)
+		case ResourceTypeFontDir:
+			if res.Name != "FONTDIR" {
+				t.Errorf("the font directory is named %q", res.Name)
+			}
+			fontDir = res.Data
+		}
+	}
+	if !reflect.DeepEqual(fontIds, []uint{1, 10, 11, 20}) {
+		t.Errorf("got fonts %v", fontIds)
+	}
+	// a count, then each bitmap font's ordinal, the first 113 bytes of its header, its device name and its face name;
+	// TrueType fonts are not listed
+	expected := bytes.Join([][]byte{
+		hexData(t, "0300"),
+		hexData(t, "0100"), small[:113], hexData(t, "00 536D616C6C00"),
+		hexData(t, "0A00"), small[:113], hexData(t, "00 536D616C6C00"),
+		hexData(t, "0B00"), large[:113], hexData(t, "00 4C6172676500"),
+	}, nil)
+	if !bytes.Equal(fontDir, expected) {
+		t.Errorf("got font directory % X, expected % X", fontDir, expected)
+	}
+}
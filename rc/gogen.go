@@ -0,0 +1,446 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type resourceTypeName struct {
+	Type    ResourceType
+	GoName  string
+	WinName string
+}
+
+var resourceTypeNames = []resourceTypeName{
+	{Type: ResourceTypeVersion,      GoName: "Version",      WinName: "RT_VERSION"},
+	{Type: ResourceTypeMessageTable, GoName: "MessageTable", WinName: "RT_MESSAGETABLE"},
+	{Type: ResourceTypeManifest,     GoName: "Manifest",     WinName: "RT_MANIFEST"},
+	{Type: ResourceTypeDialog,       GoName: "Dialog",       WinName: "RT_DIALOG"},
+	{Type: ResourceTypeMenu,         GoName: "Menu",         WinName: "RT_MENU"},
+	{Type: ResourceTypeAccelerator,  GoName: "Accelerators", WinName: "RT_ACCELERATOR"},
+	{Type: ResourceTypeFont,         GoName: "Font",         WinName: "RT_FONT"},
+	{Type: ResourceTypeFontDir,      GoName: "FontDir",      WinName: "RT_FONTDIR"},
+	{Type: ResourceTypeString,       GoName: "String",       WinName: "RT_STRING"},
+	{Type: ResourceTypeIcon,         GoName: "Icon",         WinName: "RT_ICON"},
+	{Type: ResourceTypeGroupIcon,    GoName: "GroupIcon",    WinName: "RT_GROUP_ICON"},
+	{Type: ResourceTypeCursor,       GoName: "Cursor",       WinName: "RT_CURSOR"},
+	{Type: ResourceTypeGroupCursor,  GoName: "GroupCursor",  WinName: "RT_GROUP_CURSOR"},
+	{Type: ResourceTypeBitmap,       GoName: "Bitmap",       WinName: "RT_BITMAP"},
+	{Type: ResourceTypeRCData,       GoName: "RCData",       WinName: "RT_RCDATA"},
+	{Type: ResourceTypeHTML,         GoName: "HTML",         WinName: "RT_HTML"},
+}
+
+func lookupResourceTypeName(resourceType ResourceType) (resourceTypeName, bool) {
+	for _, name := range resourceTypeNames {
+		if name.Type == resourceType {
+			return name, true
+		}
+	}
+	return resourceTypeName{}, false
+}
+
+type goAccessor struct {
+	FuncName string
+	TypeName string
+	Type     uint
+	Id       uint
+	Name     string
+}
+
+type goPackage struct {
+	PackageName string
+	Language    uint16
+	Accessors   []goAccessor
+	HasStrings  bool // whether String is generated for the RT_STRING resources
+}
+
+// StringType is the type of the resources read by String.
+func (pkg *goPackage) StringType() uint {
+	return uint(ResourceTypeString)
+}
+
+const goCommonTemplate = `// Code generated by gorc. DO NOT EDIT.
+
+package {{.PackageName}}
+{{if .HasStrings}}
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+)
+{{end}}
+// ExecutablePath, if not empty, names a Win32 executable from which resources are read using a pure Go parser
+// instead of the running executable.  This allows the accessors to be used in tests against a cross-built binary.
+var ExecutablePath string
+
+const resourceLanguage = {{printf "0x%04X" .Language}}
+{{range .Accessors}}
+{{- if .Name}}
+// {{.FuncName}} returns the data of the {{.TypeName}} resource named {{.Name}}.
+{{- else}}
+// {{.FuncName}} returns the data of the {{.TypeName}} resource with ID {{.Id}}.
+{{- end}}
+func {{.FuncName}}() ([]byte, error) {
+	return loadResource({{.Type}}, {{.Id}}, {{printf "%q" .Name}})
+}
+{{end}}
+{{- if .HasStrings}}
+// String returns the string with the given ID from the RT_STRING resources.
+func String(id uint16) (string, error) {
+	data, err := loadResource({{.StringType}}, uint(id)/16+1, "")
+	if err != nil {
+		return "", err
+	}
+	for i := uint16(0); ; i++ {
+		if len(data) < 2 {
+			return "", fmt.Errorf("string table block for string %d is truncated", id)
+		}
+		length := int(binary.LittleEndian.Uint16(data))
+		if len(data) < 2+2*length {
+			return "", fmt.Errorf("string table block for string %d is truncated", id)
+		}
+		if i == id%16 {
+			if length == 0 {
+				return "", fmt.Errorf("string %d not found", id)
+			}
+			chars := make([]uint16, length)
+			for j := range chars {
+				chars[j] = binary.LittleEndian.Uint16(data[2+2*j:])
+			}
+			return string(utf16.Decode(chars)), nil
+		}
+		data = data[2+2*length:]
+	}
+}
+{{end}}`
+
+const goWindowsTemplate = `// Code generated by gorc. DO NOT EDIT.
+
+//go:build windows
+// +build windows
+
+package {{.PackageName}}
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	procFindResourceExW = modkernel32.NewProc("FindResourceExW")
+	procLoadResource    = modkernel32.NewProc("LoadResource")
+	procLockResource    = modkernel32.NewProc("LockResource")
+	procRtlMoveMemory   = modkernel32.NewProc("RtlMoveMemory")
+	procSizeofResource  = modkernel32.NewProc("SizeofResource")
+)
+
+func loadResource(resourceType uint, resourceId uint, resourceName string) ([]byte, error) {
+	if ExecutablePath != "" {
+		return loadResourceFromFile(ExecutablePath, resourceType, resourceId, resourceName)
+	}
+	var hResInfo uintptr
+	var err error
+	if resourceName != "" {
+		name, _ := syscall.UTF16PtrFromString(resourceName)
+		hResInfo, _, err = procFindResourceExW.Call(0, uintptr(resourceType), uintptr(unsafe.Pointer(name)), resourceLanguage)
+	} else {
+		hResInfo, _, err = procFindResourceExW.Call(0, uintptr(resourceType), uintptr(resourceId), resourceLanguage)
+	}
+	if hResInfo == 0 {
+		return nil, fmt.Errorf("FindResourceEx failed for resource type %d, ID %d (%s)", resourceType, resourceId, err)
+	}
+	size, _, err := procSizeofResource.Call(0, hResInfo)
+	if size == 0 {
+		return nil, fmt.Errorf("SizeofResource failed for resource type %d, ID %d (%s)", resourceType, resourceId, err)
+	}
+	hResData, _, err := procLoadResource.Call(0, hResInfo)
+	if hResData == 0 {
+		return nil, fmt.Errorf("LoadResource failed for resource type %d, ID %d (%s)", resourceType, resourceId, err)
+	}
+	ptr, _, err := procLockResource.Call(hResData)
+	if ptr == 0 {
+		return nil, fmt.Errorf("LockResource failed for resource type %d, ID %d (%s)", resourceType, resourceId, err)
+	}
+	data := make([]byte, size)
+	procRtlMoveMemory.Call(uintptr(unsafe.Pointer(&data[0])), ptr, size)
+	return data, nil
+}
+`
+
+const goOtherTemplate = `// Code generated by gorc. DO NOT EDIT.
+
+//go:build !windows
+// +build !windows
+
+package {{.PackageName}}
+
+import (
+	"os"
+)
+
+func loadResource(resourceType uint, resourceId uint, resourceName string) ([]byte, error) {
+	fileName := ExecutablePath
+	if fileName == "" {
+		var err error
+		if fileName, err = os.Executable(); err != nil {
+			return nil, err
+		}
+	}
+	return loadResourceFromFile(fileName, resourceType, resourceId, resourceName)
+}
+`
+
+const goPETemplate = `// Code generated by gorc. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import (
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+const imageDirectoryEntryResource = 2
+
+func resourceSectionData(f *pe.File) ([]byte, uint32, error) {
+	var dataDirectory pe.DataDirectory
+	switch header := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		if header.NumberOfRvaAndSizes > imageDirectoryEntryResource {
+			dataDirectory = header.DataDirectory[imageDirectoryEntryResource]
+		}
+	case *pe.OptionalHeader64:
+		if header.NumberOfRvaAndSizes > imageDirectoryEntryResource {
+			dataDirectory = header.DataDirectory[imageDirectoryEntryResource]
+		}
+	}
+	if dataDirectory.VirtualAddress == 0 {
+		return nil, 0, errors.New("executable has no resource section")
+	}
+	for _, section := range f.Sections {
+		virtualSize := section.VirtualSize
+		if virtualSize == 0 {
+			virtualSize = section.Size
+		}
+		if dataDirectory.VirtualAddress >= section.VirtualAddress &&
+			uint64(dataDirectory.VirtualAddress) < uint64(section.VirtualAddress)+uint64(virtualSize) {
+			data, err := section.Data()
+			if err != nil {
+				return nil, 0, err
+			}
+			offset := dataDirectory.VirtualAddress - section.VirtualAddress
+			if uint64(offset)+16 > uint64(len(data)) || uint64(offset)+16 > uint64(virtualSize) {
+				return nil, 0, errors.New("resource directory is not stored in the file")
+			}
+			return data[offset:], dataDirectory.VirtualAddress, nil
+		}
+	}
+	return nil, 0, errors.New("resource directory is not contained in any section")
+}
+
+func resourceEntryName(rsrc []byte, offset uint32) (string, error) {
+	if int(offset)+2 > len(rsrc) {
+		return "", errors.New("resource name is truncated")
+	}
+	length := uint32(binary.LittleEndian.Uint16(rsrc[offset:]))
+	if int(offset+2+2*length) > len(rsrc) {
+		return "", errors.New("resource name is truncated")
+	}
+	chars := make([]uint16, length)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(rsrc[offset+2+2*uint32(i):])
+	}
+	return string(utf16.Decode(chars)), nil
+}
+
+// findResourceEntry searches the resource directory at the given offset for an entry with the given name, or with
+// the given ID if name is empty.  If id is negative, the first entry is returned.
+func findResourceEntry(rsrc []byte, offset uint32, id int, name string) (uint32, bool, error) {
+	if int(offset)+16 > len(rsrc) {
+		return 0, false, errors.New("resource directory is truncated")
+	}
+	namedCount := binary.LittleEndian.Uint16(rsrc[offset+12:])
+	idCount := binary.LittleEndian.Uint16(rsrc[offset+14:])
+	entries := offset + 16
+	for i := uint32(0); i < uint32(namedCount)+uint32(idCount); i++ {
+		entry := entries + 8*i
+		if int(entry)+8 > len(rsrc) {
+			return 0, false, errors.New("resource directory is truncated")
+		}
+		entryId := binary.LittleEndian.Uint32(rsrc[entry:])
+		if name != "" {
+			if entryId&0x80000000 == 0 {
+				continue
+			}
+			entryName, err := resourceEntryName(rsrc, entryId&0x7FFFFFFF)
+			if err != nil {
+				return 0, false, err
+			}
+			if !strings.EqualFold(entryName, name) {
+				continue
+			}
+		} else if id >= 0 && (entryId&0x80000000 != 0 || entryId != uint32(id)) {
+			continue
+		}
+		return binary.LittleEndian.Uint32(rsrc[entry+4:]), true, nil
+	}
+	return 0, false, nil
+}
+
+func loadResourceFromFile(fileName string, resourceType uint, resourceId uint, resourceName string) ([]byte, error) {
+	f, err := pe.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rsrc, rsrcAddress, err := resourceSectionData(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", fileName, err)
+	}
+	offset := uint32(0)
+	for level, id := range []int{int(resourceType), int(resourceId), resourceLanguage} {
+		name := ""
+		if level == 1 {
+			name = resourceName
+		}
+		next, found, err := findResourceEntry(rsrc, offset&0x7FFFFFFF, id, name)
+		if err == nil && !found && level == 2 {
+			// fall back to any language, as FindResource would
+			next, found, err = findResourceEntry(rsrc, offset&0x7FFFFFFF, -1, "")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", fileName, err)
+		} else if !found && resourceName != "" {
+			return nil, fmt.Errorf("%s: resource type %d, name %s not found", fileName, resourceType, resourceName)
+		} else if !found {
+			return nil, fmt.Errorf("%s: resource type %d, ID %d not found", fileName, resourceType, resourceId)
+		}
+		offset = next
+	}
+	if offset&0x80000000 != 0 || int(offset)+16 > len(rsrc) {
+		return nil, fmt.Errorf("%s: invalid resource data entry", fileName)
+	}
+	dataAddress := binary.LittleEndian.Uint32(rsrc[offset:])
+	size := binary.LittleEndian.Uint32(rsrc[offset+4:])
+	start := dataAddress - rsrcAddress
+	if dataAddress < rsrcAddress || uint64(start)+uint64(size) > uint64(len(rsrc)) {
+		return nil, fmt.Errorf("%s: resource data lies outside the resource section", fileName)
+	}
+	data := make([]byte, size)
+	copy(data, rsrc[start:start+size])
+	return data, nil
+}
+`
+
+var goPackageFiles = []struct {
+	FileName string
+	Template string
+}{
+	{FileName: "resources.go",         Template: goCommonTemplate},
+	{FileName: "resources_windows.go", Template: goWindowsTemplate},
+	{FileName: "resources_other.go",   Template: goOtherTemplate},
+	{FileName: "resources_pe.go",      Template: goPETemplate},
+}
+
+// goIdentifierSuffix converts a resource name into a string that can be appended to a Go identifier.
+func goIdentifierSuffix(name string) string {
+	var suffix strings.Builder
+	for _, c := range name {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			suffix.WriteRune(c)
+		} else {
+			suffix.WriteRune('_')
+		}
+	}
+	return suffix.String()
+}
+
+// GenerateGoPackage writes a Go package to the given directory containing one accessor function for each resource,
+// except that string tables are read with a single String function that takes the ID of a string.
+// The package name is taken from the last element of the directory path, which must be a valid Go identifier.
+func GenerateGoPackage(dir string, language Language, resources []*Resource) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	packageName := filepath.Base(absDir)
+	if !token.IsIdentifier(packageName) {
+		return errors.New(fmt.Sprintf("the directory name %s is not a valid Go package name", packageName))
+	}
+	pkg := goPackage{
+		PackageName: packageName,
+		Language:    uint16(language),
+		Accessors:   make([]goAccessor, 0, len(resources)),
+	}
+	for _, res := range resources {
+		if res.Type == ResourceTypeString {
+			// the strings are read by ID through String rather than a block at a time
+			pkg.HasStrings = true
+			continue
+		}
+		typeName, ok := lookupResourceTypeName(res.Type)
+		if !ok {
+			return errors.New(fmt.Sprintf("cannot generate an accessor for resource type %d", res.Type))
+		}
+		accessor := goAccessor{
+			FuncName: fmt.Sprintf("%s%d", typeName.GoName, res.Id),
+			TypeName: typeName.WinName,
+			Type:     uint(res.Type),
+			Id:       res.Id,
+			Name:     res.Name,
+		}
+		if res.Name != "" {
+			accessor.FuncName = typeName.GoName + goIdentifierSuffix(res.Name)
+		}
+		pkg.Accessors = append(pkg.Accessors, accessor)
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	for _, file := range goPackageFiles {
+		tmpl := template.Must(template.New(file.FileName).Parse(file.Template))
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, &pkg); err != nil {
+			return err
+		}
+		source, err := format.Source(buf.Bytes())
+		if err != nil {
+			return errors.New(fmt.Sprintf("failed to format generated file %s (%s)", file.FileName, err))
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, file.FileName), source, 0666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGoPackageName(t *testing.T) {
+	dir := t.TempDir()
+	resources := []*Resource{{Type: ResourceTypeManifest, Id: 1, Data: []byte(testManifest)}}
+	for _, name := range []string{"hello-res", "1res", "type"} {
+		if err := GenerateGoPackage(filepath.Join(dir, name), LanguageNeutral, resources); err == nil {
+			t.Errorf("package %s was generated", name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("directory %s was created", name)
+		}
+	}
+	if err := GenerateGoPackage(filepath.Join(dir, "hellores"), LanguageNeutral, resources); err != nil {
+		t.Fatalf("failed to generate package hellores: %s", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "hellores", "resources.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\npackage hellores\n") {
+		t.Errorf("resources.go does not declare package hellores")
+	}
+}
+
+// TestGenerateGoPackageCrossBuild builds a Windows executable that links a .syso file together with a generated
+// package, then reads the resources back from it with the generated accessors through ExecutablePath.
+func TestGenerateGoPackageCrossBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("cross-building an executable is slow")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go tool is not available")
+	}
+	dir := t.TempDir()
+	jsonData, _, err := DecodeJSONC([]byte(`{
+		"language": "en-US",
+		"rcdata": [{"id": 10, "text": "\u0000\u0001binaryÿ"}, {"name": "Config", "text": "key=value"}],
+		"stringTable": [{"id": 1, "text": "One"}, {"id": 17, "text": "Seventeen é"}, {"id": 4000, "text": "Far"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := ParseResources(jsonData, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateGoPackage(filepath.Join(dir, "res"), set.Language, set.Resources); err != nil {
+		t.Fatal(err)
+	}
+	var syso bytes.Buffer
+	if err := set.WriteSyso(&syso, "amd64"); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":                      "module example.com/restest\n\ngo 1.18\n",
+		"app/rsrc_windows_amd64.syso": syso.String(),
+		"app/main.go":                 "package main\n\nimport _ \"example.com/restest/res\"\n\nfunc main() {}\n",
+		"check/main.go": `package main
+
+import (
+	"fmt"
+	"os"
+
+	"example.com/restest/res"
+)
+
+func main() {
+	res.ExecutablePath = os.Args[1]
+	for _, load := range []func() ([]byte, error){res.RCData10, res.RCDataConfig} {
+		data, err := load()
+		fmt.Printf("%q %v\n", data, err)
+	}
+	for _, id := range []uint16{1, 17, 4000, 2} {
+		text, err := res.String(id)
+		fmt.Printf("%q %v\n", text, err)
+	}
+}
+`,
+	})
+	run := func(env []string, args ...string) string {
+		cmd := exec.Command(goTool, args...)
+		cmd.Dir = dir
+		cmd.Env = append(append(os.Environ(), "CGO_ENABLED=0", "GOWORK=off"), env...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("go %s failed: %s\n%s", strings.Join(args, " "), err, output)
+		}
+		return string(output)
+	}
+	run([]string{"GOOS=windows", "GOARCH=amd64"}, "build", "-o", "app.exe", "./app")
+	output := run(nil, "run", "./check", filepath.Join(dir, "app.exe"))
+	expected := strings.Join([]string{
+		`"\x00\x01binaryÿ" <nil>`,
+		`"key=value" <nil>`,
+		`"One" <nil>`,
+		`"Seventeen é" <nil>`,
+		`"Far" <nil>`,
+		`"" string 2 not found`,
+		"",
+	}, "\n")
+	if output != expected {
+		t.Errorf("the resources read back from the executable are\n%s\nexpected\n%s", output, expected)
+	}
+}
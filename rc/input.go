@@ -0,0 +1,384 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeResourceFile decodes a resource description, choosing the format from the file extension: .json and .jsonc
+// for JSON with comments, .yaml and .yml for YAML and .toml for TOML.  Whatever the format, integers are decoded as
+// int64 and other numbers as float64, so that the result can be passed to ParseResources.  For TOML files, the source
+// map gives the positions of tables and keys but not of the values inside arrays and inline tables.
+func DecodeResourceFile(fileName string, data []byte) (map[string]interface{}, SourceMap, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json", ".jsonc":
+		return DecodeJSONC(data)
+	case ".yaml", ".yml":
+		return decodeYAML(data)
+	case ".toml":
+		return decodeTOML(data)
+	default:
+		return nil, nil, errors.New(fmt.Sprintf("unsupported file type: %s (expected .json, .jsonc, .yaml, .yml "+
+			"or .toml)", fileName))
+	}
+}
+
+var yamlErrorPattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func decodeYAML(data []byte) (map[string]interface{}, SourceMap, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		if match := yamlErrorPattern.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			return nil, nil, &SyntaxError{Pos: Position{Line: line}, Msg: match[2]}
+		}
+		// some errors, such as those for control characters, do not give a line
+		return nil, nil, &SyntaxError{Msg: strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) != 1 ||
+		document.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, &SyntaxError{Pos: Position{Line: 1, Column: 1}, Msg: "expected a YAML mapping"}
+	}
+	sourceMap := make(SourceMap)
+	value, err := convertYAMLNode(document.Content[0], "", sourceMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	return value.(map[string]interface{}), sourceMap, nil
+}
+
+func convertYAMLNode(node *yaml.Node, pointer string, sourceMap SourceMap) (interface{}, error) {
+	sourceMap[pointer] = Position{Line: node.Line, Column: node.Column}
+	switch node.Kind {
+	case yaml.AliasNode:
+		return convertYAMLNode(node.Alias, pointer, sourceMap)
+	case yaml.MappingNode:
+		obj := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			if keyNode.Kind != yaml.ScalarNode || keyNode.Tag == "!!merge" {
+				return nil, &SyntaxError{
+					Pos: Position{Line: keyNode.Line, Column: keyNode.Column},
+					Msg: "mapping keys must be strings",
+				}
+			}
+			// yaml.v3 only reports duplicate keys when decoding into a map, not into a node
+			if _, ok := obj[keyNode.Value]; ok {
+				return nil, &SyntaxError{
+					Pos: Position{Line: keyNode.Line, Column: keyNode.Column},
+					Msg: fmt.Sprintf("duplicate key %q", keyNode.Value),
+				}
+			}
+			value, err := convertYAMLNode(valueNode, pointer+"/"+escapeJSONPointer(keyNode.Value), sourceMap)
+			if err != nil {
+				return nil, err
+			}
+			obj[keyNode.Value] = value
+		}
+		return obj, nil
+	case yaml.SequenceNode:
+		array := make([]interface{}, 0, len(node.Content))
+		for i, elemNode := range node.Content {
+			value, err := convertYAMLNode(elemNode, fmt.Sprintf("%s/%d", pointer, i), sourceMap)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, &SyntaxError{Pos: Position{Line: node.Line, Column: node.Column}, Msg: err.Error()}
+		}
+		return normalizeValue(value), nil
+	}
+}
+
+func decodeTOML(data []byte) (map[string]interface{}, SourceMap, error) {
+	var obj map[string]interface{}
+	if _, err := toml.Decode(string(data), &obj); err != nil {
+		if parseErr, ok := err.(toml.ParseError); ok {
+			return nil, nil, &SyntaxError{
+				Pos: Position{Line: parseErr.Position.Line, Column: parseErr.Position.Col},
+				Msg: parseErr.Message,
+			}
+		}
+		return nil, nil, err
+	}
+	return normalizeValue(obj).(map[string]interface{}), tomlSourceMap(data), nil
+}
+
+// tomlSourceMap finds the positions of the tables and key/value pairs in a TOML document that has already been decoded
+// without error.  The TOML decoder does not export the positions of keys, so the document is scanned again.  Values
+// inside arrays and inline tables are not mapped; errors in them are reported at the enclosing value.
+func tomlSourceMap(data []byte) SourceMap {
+	d := &jsoncDecoder{data: data, line: 1, sourceMap: SourceMap{"": Position{Line: 1, Column: 1}}}
+	arrayIndexes := make(map[string]int)
+	table := ""
+	for {
+		d.skipTOMLSpace()
+		if d.offset >= len(d.data) {
+			return d.sourceMap
+		}
+		pos := d.pos()
+		if d.data[d.offset] == '[' {
+			isArray := d.offset+1 < len(d.data) && d.data[d.offset+1] == '['
+			d.advance()
+			if isArray {
+				d.advance()
+			}
+			keys := d.scanTOMLKey()
+			if len(keys) == 0 {
+				d.skipTOMLLine()
+				continue
+			}
+			// a table header names the last element of any array of tables on its path
+			table = ""
+			for i, key := range keys {
+				table += "/" + escapeJSONPointer(key)
+				if _, ok := d.sourceMap[table]; !ok {
+					d.sourceMap[table] = pos
+				}
+				if index, ok := arrayIndexes[table]; ok && i < len(keys)-1 {
+					table += "/" + strconv.Itoa(index)
+				}
+			}
+			if isArray {
+				index, ok := arrayIndexes[table]
+				if ok {
+					index++
+				}
+				arrayIndexes[table] = index
+				table += "/" + strconv.Itoa(index)
+			}
+			d.sourceMap[table] = pos
+			d.skipTOMLLine()
+			continue
+		}
+		keys := d.scanTOMLKey()
+		d.skipTOMLBlank()
+		if len(keys) == 0 || d.offset >= len(d.data) || d.data[d.offset] != '=' {
+			d.skipTOMLLine()
+			continue
+		}
+		d.advance()
+		d.skipTOMLBlank()
+		pointer := table
+		for _, key := range keys {
+			pointer += "/" + escapeJSONPointer(key)
+			if _, ok := d.sourceMap[pointer]; !ok {
+				d.sourceMap[pointer] = pos
+			}
+		}
+		d.sourceMap[pointer] = d.pos()
+		d.skipTOMLValue()
+	}
+}
+
+// skipTOMLBlank skips spaces and tabs.
+func (d *jsoncDecoder) skipTOMLBlank() {
+	for d.offset < len(d.data) && (d.data[d.offset] == ' ' || d.data[d.offset] == '\t') {
+		d.advance()
+	}
+}
+
+// skipTOMLSpace skips white space, line breaks and comments.
+func (d *jsoncDecoder) skipTOMLSpace() {
+	for d.offset < len(d.data) {
+		switch d.data[d.offset] {
+		case ' ', '\t', '\r', '\n':
+			d.advance()
+		case '#':
+			d.skipTOMLLine()
+		default:
+			return
+		}
+	}
+}
+
+// skipTOMLLine skips to the end of the line.
+func (d *jsoncDecoder) skipTOMLLine() {
+	for d.offset < len(d.data) && d.data[d.offset] != '\n' {
+		d.advance()
+	}
+}
+
+// scanTOMLKey reads a key, which may be dotted and may contain quoted parts, and returns its parts.
+func (d *jsoncDecoder) scanTOMLKey() []string {
+	var keys []string
+	for {
+		d.skipTOMLBlank()
+		start := d.offset
+		if d.offset < len(d.data) && (d.data[d.offset] == '"' || d.data[d.offset] == '\'') {
+			d.skipTOMLString()
+			quoted := string(d.data[start:d.offset])
+			key, err := strconv.Unquote(quoted)
+			if quoted[0] == '\'' || err != nil {
+				key = quoted[1 : len(quoted)-1]
+			}
+			keys = append(keys, key)
+		} else {
+			for d.offset < len(d.data) && isTOMLBareKeyByte(d.data[d.offset]) {
+				d.advance()
+			}
+			if d.offset == start {
+				return keys
+			}
+			keys = append(keys, string(d.data[start:d.offset]))
+		}
+		d.skipTOMLBlank()
+		if d.offset >= len(d.data) || d.data[d.offset] != '.' {
+			return keys
+		}
+		d.advance()
+	}
+}
+
+func isTOMLBareKeyByte(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// skipTOMLString skips a basic or literal string, either of which may be a multi-line string.
+func (d *jsoncDecoder) skipTOMLString() {
+	quote := d.data[d.offset]
+	delimiter := []byte{quote}
+	if bytes.HasPrefix(d.data[d.offset:], []byte{quote, quote, quote}) {
+		delimiter = []byte{quote, quote, quote}
+	}
+	d.offset += len(delimiter)
+	for d.offset < len(d.data) {
+		if quote == '"' && d.data[d.offset] == '\\' && d.offset+1 < len(d.data) {
+			d.advance()
+			d.advance()
+			continue
+		}
+		if bytes.HasPrefix(d.data[d.offset:], delimiter) {
+			d.offset += len(delimiter)
+			// a multi-line string may end with up to two quotes before its delimiter
+			for i := 0; i < 2 && len(delimiter) == 3 && d.offset < len(d.data) && d.data[d.offset] == quote; i++ {
+				d.offset++
+			}
+			return
+		}
+		if len(delimiter) == 1 && d.data[d.offset] == '\n' {
+			return
+		}
+		d.advance()
+	}
+}
+
+// skipTOMLValue skips a value, including arrays and inline tables that continue over several lines, and the rest of
+// the line after it.
+func (d *jsoncDecoder) skipTOMLValue() {
+	depth := 0
+	for d.offset < len(d.data) {
+		switch d.data[d.offset] {
+		case '"', '\'':
+			d.skipTOMLString()
+		case '[', '{':
+			depth++
+			d.advance()
+		case ']', '}':
+			depth--
+			d.advance()
+		case '#':
+			d.skipTOMLLine()
+		case '\n':
+			if depth <= 0 {
+				return
+			}
+			d.advance()
+		default:
+			d.advance()
+		}
+	}
+}
+
+// normalizeValue converts the values produced by the YAML and TOML decoders and by encoding/json to the types
+// produced by DecodeJSONC: integers, including floating-point numbers and json.Numbers with integral values, become
+// int64, dates and times become strings, and typed lists and tables become []interface{} and map[string]interface{}.
+// Lists and objects are copied rather than changed in place.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return float64(v)
+		}
+		return int64(v)
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return int64(v)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return normalizeValue(f)
+		}
+		return value
+	case time.Time:
+		// the TOML decoder marks local dates and times with these zone names
+		switch v.Location().String() {
+		case "date-local":
+			return v.Format("2006-01-02")
+		case "time-local":
+			return v.Format("15:04:05.999999999")
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05.999999999")
+		default:
+			return v.Format(time.RFC3339Nano)
+		}
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			obj[key] = normalizeValue(elem)
+		}
+		return obj
+	case []map[string]interface{}:
+		array := make([]interface{}, len(v))
+		for i, elem := range v {
+			array[i] = normalizeValue(elem)
+		}
+		return array
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for i, elem := range v {
+			array[i] = normalizeValue(elem)
+		}
+		return array
+	default:
+		return value
+	}
+}
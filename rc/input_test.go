@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"testing"
+)
+
+func TestDecodeTOMLSourceMap(t *testing.T) {
+	data := []byte(`# resources
+language = "en-US"
+
+[version]
+fileVersion = "1.2.3.4"
+stringFileInfo.companyName = "MongoDB, Inc."
+fileFlags = [
+	"VS_FF_DEBUG", # comment with ] in it
+	"VS_FF_PRERELEASE",
+]
+comments = """
+multi-line = "string"
+"""
+
+[[menus]]
+id = 1
+
+[[menus]]
+"id" = 2
+
+[menus.items]
+text = 'File'
+`)
+	_, sourceMap, err := DecodeResourceFile("resources.toml", data)
+	if err != nil {
+		t.Fatalf("DecodeResourceFile failed: %s", err)
+	}
+	for pointer, expected := range map[string]Position{
+		"":                                    {Line: 1, Column: 1},
+		"/language":                           {Line: 2, Column: 12},
+		"/version":                            {Line: 4, Column: 1},
+		"/version/fileVersion":                {Line: 5, Column: 15},
+		"/version/stringFileInfo":             {Line: 6, Column: 1},
+		"/version/stringFileInfo/companyName": {Line: 6, Column: 30},
+		"/version/fileFlags":                  {Line: 7, Column: 13},
+		"/version/comments":                   {Line: 11, Column: 12},
+		"/menus":                              {Line: 15, Column: 1},
+		"/menus/0":                            {Line: 15, Column: 1},
+		"/menus/0/id":                         {Line: 16, Column: 6},
+		"/menus/1":                            {Line: 18, Column: 1},
+		"/menus/1/id":                         {Line: 19, Column: 8},
+		"/menus/1/items":                      {Line: 21, Column: 1},
+		"/menus/1/items/text":                 {Line: 22, Column: 8},
+	} {
+		if pos, ok := sourceMap[pointer]; !ok || pos != expected {
+			t.Errorf("%q is at %s, expected %s", pointer, pos, expected)
+		}
+	}
+	if _, ok := sourceMap["/version/multi-line"]; ok {
+		t.Errorf("a line inside a multi-line string was mapped")
+	}
+}
+
+func TestDecodeYAMLErrors(t *testing.T) {
+	for _, test := range []struct {
+		Data string
+		Pos  Position
+		Msg  string
+	}{
+		{"language: en-US\nversion: {}\nlanguage: de-DE\n", Position{Line: 3, Column: 1}, `duplicate key "language"`},
+		{"version:\n  fileVersion: 1.0\n  fileVersion: 2.0\n", Position{Line: 3, Column: 3},
+			`duplicate key "fileVersion"`},
+		{"menus:\n  - id: 1\n    id: 2\n", Position{Line: 3, Column: 5}, `duplicate key "id"`},
+		{"? [a]\n: 1\n", Position{Line: 1, Column: 3}, "mapping keys must be strings"},
+		{"- 1\n", Position{Line: 1, Column: 1}, "expected a YAML mapping"},
+		{"version: [1\n", Position{Line: 1}, "did not find expected ',' or ']'"},
+		{"language: \x01\n", Position{}, "control characters are not allowed"},
+		{"version: a: b\n", Position{}, "mapping values are not allowed in this context"},
+	} {
+		_, _, err := DecodeResourceFile("resources.yaml", []byte(test.Data))
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("%q: got %v, expected a syntax error", test.Data, err)
+		} else if syntaxErr.Pos != test.Pos || syntaxErr.Msg != test.Msg {
+			t.Errorf("%q: got %q at %+v, expected %q at %+v", test.Data, syntaxErr.Msg, syntaxErr.Pos, test.Msg,
+				test.Pos)
+		}
+	}
+	if err := (&SyntaxError{Msg: "invalid"}).Error(); err != "invalid" {
+		t.Errorf("a syntax error without a position reads %q", err)
+	}
+}
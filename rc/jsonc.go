@@ -0,0 +1,337 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position is a 1-based line and column in a source file.  Columns count characters rather than bytes; a zero column
+// means that only the line is known.  File names the source file when the data was merged from several files.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (pos Position) String() string {
+	if pos.Column == 0 {
+		return strconv.Itoa(pos.Line)
+	}
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+// SourceMap records the position at which each value in a decoded file starts, keyed by JSON pointer.  The root
+// object is stored under the empty pointer.
+type SourceMap map[string]Position
+
+// Lookup returns the position of the value at the pointer, or of the nearest enclosing value if the pointer does not
+// refer to a value in the file (for example, a field that is required but missing).
+func (sourceMap SourceMap) Lookup(pointer string) (Position, bool) {
+	for {
+		if pos, ok := sourceMap[pointer]; ok {
+			return pos, true
+		}
+		if pointer == "" {
+			return Position{}, false
+		}
+		pointer = pointer[:strings.LastIndex(pointer, "/")]
+	}
+}
+
+// SyntaxError describes malformed input to DecodeJSONC.  A zero line means that the position is not known.
+type SyntaxError struct {
+	Pos Position
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Pos.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+type jsoncDecoder struct {
+	data      []byte
+	offset    int
+	line      int
+	lineStart int
+	sourceMap SourceMap
+}
+
+func (d *jsoncDecoder) pos() Position {
+	return Position{Line: d.line, Column: utf8.RuneCount(d.data[d.lineStart:d.offset]) + 1}
+}
+
+func (d *jsoncDecoder) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Pos: d.pos(), Msg: fmt.Sprintf(format, args...)}
+}
+
+func (d *jsoncDecoder) advance() {
+	if d.data[d.offset] == '\n' {
+		d.line++
+		d.lineStart = d.offset + 1
+	}
+	d.offset++
+}
+
+// skipSpace skips white space and both // and /* */ comments.
+func (d *jsoncDecoder) skipSpace() error {
+	for d.offset < len(d.data) {
+		switch d.data[d.offset] {
+		case ' ', '\t', '\r', '\n':
+			d.advance()
+		case '/':
+			if d.offset+1 >= len(d.data) {
+				return d.errorf("unexpected character '/'")
+			}
+			switch d.data[d.offset+1] {
+			case '/':
+				for d.offset < len(d.data) && d.data[d.offset] != '\n' {
+					d.advance()
+				}
+			case '*':
+				start := d.pos()
+				d.offset += 2
+				for {
+					if d.offset+1 >= len(d.data) {
+						return &SyntaxError{Pos: start, Msg: "unterminated comment"}
+					}
+					if d.data[d.offset] == '*' && d.data[d.offset+1] == '/' {
+						d.offset += 2
+						break
+					}
+					d.advance()
+				}
+			default:
+				return d.errorf("unexpected character '/'")
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (d *jsoncDecoder) peek() (byte, error) {
+	if err := d.skipSpace(); err != nil {
+		return 0, err
+	}
+	if d.offset >= len(d.data) {
+		return 0, d.errorf("unexpected end of input")
+	}
+	return d.data[d.offset], nil
+}
+
+func (d *jsoncDecoder) decodeValue(pointer string) (interface{}, error) {
+	c, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+	d.sourceMap[pointer] = d.pos()
+	switch {
+	case c == '{':
+		return d.decodeObject(pointer)
+	case c == '[':
+		return d.decodeArray(pointer)
+	case c == '"':
+		return d.decodeString()
+	case c == '-' || c >= '0' && c <= '9':
+		return d.decodeNumber()
+	default:
+		for _, literal := range []struct {
+			Text  string
+			Value interface{}
+		}{{"true", true}, {"false", false}, {"null", nil}} {
+			if bytes.HasPrefix(d.data[d.offset:], []byte(literal.Text)) {
+				d.offset += len(literal.Text)
+				return literal.Value, nil
+			}
+		}
+		r, _ := utf8.DecodeRune(d.data[d.offset:])
+		return nil, d.errorf("unexpected character '%c'", r)
+	}
+}
+
+func (d *jsoncDecoder) decodeObject(pointer string) (interface{}, error) {
+	d.advance()
+	obj := make(map[string]interface{})
+	for {
+		c, err := d.peek()
+		if err != nil {
+			return nil, err
+		}
+		if c == '}' {
+			// reached either directly after '{' or after a trailing comma
+			d.advance()
+			return obj, nil
+		}
+		if c != '"' {
+			return nil, d.errorf("expected string for object key")
+		}
+		keyPos := d.pos()
+		key, err := d.decodeString()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := obj[key.(string)]; ok {
+			return nil, &SyntaxError{Pos: keyPos, Msg: fmt.Sprintf("duplicate key %q", key)}
+		}
+		if c, err = d.peek(); err != nil {
+			return nil, err
+		} else if c != ':' {
+			return nil, d.errorf("expected ':' after object key")
+		}
+		d.advance()
+		value, err := d.decodeValue(pointer + "/" + escapeJSONPointer(key.(string)))
+		if err != nil {
+			return nil, err
+		}
+		obj[key.(string)] = value
+		if c, err = d.peek(); err != nil {
+			return nil, err
+		}
+		switch c {
+		case ',':
+			d.advance()
+		case '}':
+			d.advance()
+			return obj, nil
+		default:
+			return nil, d.errorf("expected ',' or '}' after object value")
+		}
+	}
+}
+
+func (d *jsoncDecoder) decodeArray(pointer string) (interface{}, error) {
+	d.advance()
+	array := make([]interface{}, 0)
+	for {
+		c, err := d.peek()
+		if err != nil {
+			return nil, err
+		}
+		if c == ']' {
+			d.advance()
+			return array, nil
+		}
+		value, err := d.decodeValue(fmt.Sprintf("%s/%d", pointer, len(array)))
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+		if c, err = d.peek(); err != nil {
+			return nil, err
+		}
+		switch c {
+		case ',':
+			d.advance()
+		case ']':
+			d.advance()
+			return array, nil
+		default:
+			return nil, d.errorf("expected ',' or ']' after array element")
+		}
+	}
+}
+
+func (d *jsoncDecoder) decodeString() (interface{}, error) {
+	start := d.pos()
+	end := d.offset + 1
+	for {
+		if end >= len(d.data) || d.data[end] == '\n' {
+			return nil, &SyntaxError{Pos: start, Msg: "unterminated string"}
+		}
+		if d.data[end] == '\\' {
+			end += 2
+			continue
+		}
+		if d.data[end] == '"' {
+			break
+		}
+		end++
+	}
+	// the token is a plain JSON string, so let encoding/json handle escapes and invalid characters
+	var value string
+	if err := json.Unmarshal(d.data[d.offset:end+1], &value); err != nil {
+		return nil, &SyntaxError{Pos: start, Msg: fmt.Sprintf("invalid string (%s)", err)}
+	}
+	d.offset = end + 1
+	return value, nil
+}
+
+func (d *jsoncDecoder) decodeNumber() (interface{}, error) {
+	start := d.pos()
+	end := d.offset
+	for end < len(d.data) && strings.IndexByte("+-0123456789.eE", d.data[end]) >= 0 {
+		end++
+	}
+	token := string(d.data[d.offset:end])
+	var check json.Number
+	if err := json.Unmarshal([]byte(token), &check); err != nil {
+		return nil, &SyntaxError{Pos: start, Msg: fmt.Sprintf("invalid number %s", token)}
+	}
+	d.offset = end
+	// integers are kept exact rather than being rounded through float64
+	if !strings.ContainsAny(token, ".eE") {
+		if value, err := strconv.ParseInt(token, 10, 64); err == nil {
+			return value, nil
+		}
+	}
+	value, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return nil, &SyntaxError{Pos: start, Msg: fmt.Sprintf("number %s is out of range", token)}
+	}
+	return value, nil
+}
+
+// DecodeJSONC decodes a JSON object that may contain // and /* */ comments and trailing commas in objects and arrays.
+// Values are decoded to the same types as encoding/json uses for an interface{}, except that integers are decoded as
+// int64.  An object may not give the same key twice.  The returned source map gives the position of every value in the
+// input.
+func DecodeJSONC(data []byte) (map[string]interface{}, SourceMap, error) {
+	d := &jsoncDecoder{data: data, line: 1, sourceMap: make(SourceMap)}
+	// skip a UTF-8 byte order mark, which some Windows editors insert
+	if bytes.HasPrefix(data, []byte("\xEF\xBB\xBF")) {
+		d.offset = 3
+		d.lineStart = 3
+	}
+	c, err := d.peek()
+	if err != nil {
+		return nil, nil, err
+	}
+	if c != '{' {
+		return nil, nil, d.errorf("expected a JSON object")
+	}
+	value, err := d.decodeValue("")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := d.skipSpace(); err != nil {
+		return nil, nil, err
+	}
+	if d.offset < len(d.data) {
+		return nil, nil, d.errorf("unexpected data after JSON object")
+	}
+	return value.(map[string]interface{}), d.sourceMap, nil
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"testing"
+)
+
+func TestDecodeJSONC(t *testing.T) {
+	data := []byte("{\n  // comment\n  \"a\": [true, false, null,],\n  /* comment */ \"b\": {\"c\": 1},\n}\n")
+	obj, sourceMap, err := DecodeJSONC(data)
+	if err != nil {
+		t.Fatalf("DecodeJSONC failed: %s", err)
+	}
+	array := obj["a"].([]interface{})
+	if len(array) != 3 || array[0] != true || array[1] != false || array[2] != nil {
+		t.Errorf("a decoded as %v", array)
+	}
+	if value := obj["b"].(map[string]interface{})["c"]; value != int64(1) {
+		t.Errorf("b.c decoded as %#v", value)
+	}
+	if pos := sourceMap["/b/c"]; pos != (Position{Line: 4, Column: 28}) {
+		t.Errorf("/b/c is at %s", pos)
+	}
+}
+
+func TestDecodeJSONCDuplicateKey(t *testing.T) {
+	data := []byte("{\n  \"a\": 1,\n  \"b\": {\"c\": 1, \"c\": 2}\n}\n")
+	_, _, err := DecodeJSONC(data)
+	syntaxErr, ok := err.(*SyntaxError)
+	if !ok {
+		t.Fatalf("DecodeJSONC returned %v, expected a syntax error", err)
+	}
+	if syntaxErr.Pos != (Position{Line: 3, Column: 17}) || syntaxErr.Msg != `duplicate key "c"` {
+		t.Errorf("DecodeJSONC returned %s", syntaxErr)
+	}
+}
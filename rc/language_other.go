@@ -0,0 +1,51 @@
+//go:build !windows
+
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+	"strings"
+)
+
+// localeLanguages holds the languages of common locales, for systems that cannot ask Windows for them.
+var localeLanguages = []struct {
+	Name     string
+	Language Language
+}{
+	{"ar-SA", 0x0401}, {"bg-BG", 0x0402}, {"cs-CZ", 0x0405}, {"da-DK", 0x0406}, {"de-AT", 0x0C07},
+	{"de-CH", 0x0807}, {"de-DE", 0x0407}, {"el-GR", 0x0408}, {"en-AU", 0x0C09}, {"en-CA", 0x1009},
+	{"en-GB", 0x0809}, {"en-US", 0x0409}, {"es-ES", 0x0C0A}, {"es-MX", 0x080A}, {"et-EE", 0x0425},
+	{"fi-FI", 0x040B}, {"fr-CA", 0x0C0C}, {"fr-FR", 0x040C}, {"he-IL", 0x040D}, {"hi-IN", 0x0439},
+	{"hr-HR", 0x041A}, {"hu-HU", 0x040E}, {"id-ID", 0x0421}, {"it-IT", 0x0410}, {"ja-JP", 0x0411},
+	{"ko-KR", 0x0412}, {"lt-LT", 0x0427}, {"lv-LV", 0x0426}, {"nb-NO", 0x0414}, {"nl-NL", 0x0413},
+	{"pl-PL", 0x0415}, {"pt-BR", 0x0416}, {"pt-PT", 0x0816}, {"ro-RO", 0x0418}, {"ru-RU", 0x0419},
+	{"sk-SK", 0x041B}, {"sl-SI", 0x0424}, {"sv-SE", 0x041D}, {"th-TH", 0x041E}, {"tr-TR", 0x041F},
+	{"uk-UA", 0x0422}, {"vi-VN", 0x042A}, {"zh-CN", 0x0804}, {"zh-HK", 0x0C04}, {"zh-TW", 0x0404},
+}
+
+// lookupLocaleName finds the language of a locale name in a built-in table of common locales.
+func lookupLocaleName(name string) (Language, error) {
+	for _, locale := range localeLanguages {
+		if strings.EqualFold(locale.Name, name) {
+			return locale.Language, nil
+		}
+	}
+	// only Windows knows every locale, so point to the numeric form that works everywhere
+	return 0, errors.New("the locale is unknown outside Windows; give its language ID instead, such as 0x0409")
+}
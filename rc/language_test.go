@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLookupLanguage(t *testing.T) {
+	for _, test := range []struct {
+		Name     string
+		Language Language
+	}{
+		{"en-US", 0x0409},
+		{"de-de", 0x0407},
+		{"0x0409", 0x0409},
+		{"0X0C0A", 0x0C0A},
+		{"1031", 0x0407},
+		{"0", LanguageNeutral},
+	} {
+		if language, err := LookupLanguage(test.Name); err != nil {
+			t.Errorf("%s: %s", test.Name, err)
+		} else if language != test.Language {
+			t.Errorf("%s: got 0x%04X, expected 0x%04X", test.Name, language, test.Language)
+		}
+	}
+	for _, name := range []string{"0x10000", "-1", "xx-YY", ""} {
+		if _, err := LookupLanguage(name); err == nil {
+			t.Errorf("%q was accepted", name)
+		}
+	}
+	if _, err := LookupLanguage("xx-YY"); runtime.GOOS != "windows" && !strings.Contains(err.Error(), "0x0409") {
+		t.Errorf("the error for an unknown locale does not suggest a language ID: %s", err)
+	}
+}
@@ -0,0 +1,30 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"github.com/winlabs/gowin32"
+)
+
+// lookupLocaleName asks Windows for the language of a locale name, so that every locale it knows is accepted.
+func lookupLocaleName(name string) (Language, error) {
+	locale, err := gowin32.LocaleFromLocaleName(name, 0)
+	if err != nil {
+		return 0, err
+	}
+	return Language(locale.Language()), nil
+}
@@ -0,0 +1,339 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LintLevel is the severity of the findings of a lint rule.
+type LintLevel uint32
+
+const (
+	LintOff LintLevel = iota
+	LintWarning
+	LintError
+)
+
+var lintLevelNames = []namedValue{
+	{Name: "off",     Value: uint32(LintOff)},
+	{Name: "warning", Value: uint32(LintWarning)},
+	{Name: "error",   Value: uint32(LintError)},
+}
+
+func (level LintLevel) String() string {
+	return lookupValueName(lintLevelNames, uint32(level))
+}
+
+// MarshalText gives the level by name, so that findings encoded as JSON show it as off, warning or error.
+func (level LintLevel) MarshalText() ([]byte, error) {
+	return []byte(level.String()), nil
+}
+
+// lintContext holds what the lint rules check: the decoded version resource, if there is one, and the target file.
+type lintContext struct {
+	Info           *VersionInfo
+	TargetFileName string
+	IsDLL          bool
+	Year           int
+}
+
+// LintRule is a named check of the version information.  Rules report problems as messages.
+type LintRule struct {
+	Name        string
+	Description string
+	Level       LintLevel // the level unless LintOptions says otherwise
+	check       func(context *lintContext) []string
+}
+
+// versionStringPrefix returns the numeric parts at the start of a version string such as 3.1.0.77 or v2.7.1-rc.3.
+func versionStringPrefix(text string) []uint64 {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "v")
+	var parts []uint64
+	for _, part := range strings.SplitN(text, ".", 4) {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		value, err := strconv.ParseUint(part[:end], 10, 16)
+		if err != nil {
+			break
+		}
+		parts = append(parts, value)
+		if end < len(part) {
+			break
+		}
+	}
+	return parts
+}
+
+// checkVersionString compares a version string with the fixed version number, as far as the string gives it.
+func checkVersionString(context *lintContext, key string, ms uint32, ls uint32) []string {
+	if context.Info == nil {
+		return nil
+	}
+	text, ok := context.Info.Lookup(key)
+	if !ok {
+		return nil
+	}
+	parts := versionStringPrefix(text)
+	if len(parts) == 0 {
+		return []string{fmt.Sprintf("%s string %q does not start with a version number", key, text)}
+	}
+	fixed := []uint64{uint64(ms >> 16), uint64(ms & 0xFFFF), uint64(ls >> 16), uint64(ls & 0xFFFF)}
+	for i, part := range parts {
+		if part != fixed[i] {
+			return []string{fmt.Sprintf("%s string %q does not match the fixed version %s", key, text,
+				formatFileVersion(ms, ls))}
+		}
+	}
+	return nil
+}
+
+var lintRules = []*LintRule{
+	{
+		Name:        "version-resource",
+		Description: "the resources include version information",
+		Level:       LintError,
+		check: func(context *lintContext) []string {
+			if context.Info == nil {
+				return []string{"there is no version resource"}
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "required-strings",
+		Description: "CompanyName, FileDescription, ProductName and LegalCopyright are set",
+		Level:       LintWarning,
+		check: func(context *lintContext) []string {
+			if context.Info == nil {
+				return nil
+			}
+			var messages []string
+			for _, key := range []string{"CompanyName", "FileDescription", "ProductName", "LegalCopyright"} {
+				if value, _ := context.Info.Lookup(key); strings.TrimSpace(value) == "" {
+					messages = append(messages, fmt.Sprintf("%s is not set", key))
+				}
+			}
+			return messages
+		},
+	},
+	{
+		Name:        "original-filename",
+		Description: "OriginalFilename is the name of the target file",
+		Level:       LintError,
+		check: func(context *lintContext) []string {
+			if context.Info == nil {
+				return nil
+			}
+			fileName := filepath.Base(context.TargetFileName)
+			value, ok := context.Info.Lookup("OriginalFilename")
+			if !ok {
+				return []string{"OriginalFilename is not set"}
+			}
+			if !strings.EqualFold(value, fileName) {
+				return []string{fmt.Sprintf("OriginalFilename is %q but the file is named %q", value, fileName)}
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "internal-name",
+		Description: "InternalName is the name of the target file, with or without its extension",
+		Level:       LintWarning,
+		check: func(context *lintContext) []string {
+			if context.Info == nil {
+				return nil
+			}
+			fileName := filepath.Base(context.TargetFileName)
+			value, ok := context.Info.Lookup("InternalName")
+			if !ok {
+				return []string{"InternalName is not set"}
+			}
+			baseName := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+			if !strings.EqualFold(value, fileName) && !strings.EqualFold(value, baseName) {
+				return []string{fmt.Sprintf("InternalName is %q but the file is named %q", value, fileName)}
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "copyright-year",
+		Description: "LegalCopyright includes the current year",
+		Level:       LintWarning,
+		check: func(context *lintContext) []string {
+			if context.Info == nil {
+				return nil
+			}
+			value, ok := context.Info.Lookup("LegalCopyright")
+			year := strconv.Itoa(context.Year)
+			if ok && value != "" && !strings.Contains(value, year) {
+				return []string{fmt.Sprintf("LegalCopyright %q does not include the year %s", value, year)}
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "file-version-string",
+		Description: "the FileVersion string agrees with the fixed file version",
+		Level:       LintError,
+		check: func(context *lintContext) []string {
+			if context.Info == nil {
+				return nil
+			}
+			fixed := &context.Info.FixedFileInfo
+			return checkVersionString(context, "FileVersion", fixed.FileVersionMS, fixed.FileVersionLS)
+		},
+	},
+	{
+		Name:        "product-version-string",
+		Description: "the ProductVersion string agrees with the fixed product version",
+		Level:       LintError,
+		check: func(context *lintContext) []string {
+			if context.Info == nil {
+				return nil
+			}
+			fixed := &context.Info.FixedFileInfo
+			return checkVersionString(context, "ProductVersion", fixed.ProductVersionMS, fixed.ProductVersionLS)
+		},
+	},
+	{
+		Name:        "file-type",
+		Description: "fileType is VFT_DLL for a DLL and VFT_APP, VFT_DRV or VFT_FONT for other images",
+		Level:       LintError,
+		check: func(context *lintContext) []string {
+			if context.Info == nil {
+				return nil
+			}
+			fileType := context.Info.FixedFileInfo.FileType
+			// drivers and font files are not marked as DLLs in their headers
+			ok, kind := fileType == VFT_APP || fileType == VFT_DRV || fileType == VFT_FONT, "not a DLL"
+			if context.IsDLL {
+				ok, kind = fileType == VFT_DLL, "a DLL"
+			}
+			if !ok {
+				return []string{fmt.Sprintf("fileType is %s but %s is %s", lookupValueName(fileTypeNames, fileType),
+					filepath.Base(context.TargetFileName), kind)}
+			}
+			return nil
+		},
+	},
+}
+
+// LintRules returns copies of the lint rules in the order in which they run, so that changing them does not change
+// how Lint checks resources.
+func LintRules() []*LintRule {
+	rules := make([]*LintRule, len(lintRules))
+	for i, rule := range lintRules {
+		ruleCopy := *rule
+		rules[i] = &ruleCopy
+	}
+	return rules
+}
+
+func lookupLintRule(name string) (*LintRule, bool) {
+	for _, rule := range lintRules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return nil, false
+}
+
+// ParseLintSetting parses a setting of the form RULE=LEVEL, where LEVEL is off, warning or error.
+func ParseLintSetting(text string) (string, LintLevel, error) {
+	i := strings.IndexByte(text, '=')
+	if i <= 0 {
+		return "", 0, errors.New("rule setting must have the form rule=level")
+	}
+	name, levelName := text[:i], text[i+1:]
+	if _, ok := lookupLintRule(name); !ok {
+		return "", 0, errors.New(fmt.Sprintf("unknown lint rule %s", name))
+	}
+	level, ok := lookupNamedValue(lintLevelNames, levelName)
+	if !ok {
+		return "", 0, errors.New(fmt.Sprintf("invalid lint level %s (expected off, warning or error)", levelName))
+	}
+	return name, LintLevel(level), nil
+}
+
+// LintFinding is a problem found by a lint rule.
+type LintFinding struct {
+	Rule    string    `json:"rule"`
+	Level   LintLevel `json:"level"`
+	Message string    `json:"message"`
+}
+
+// LintOptions controls which lint rules run and what they check against.
+type LintOptions struct {
+	// Levels overrides the levels of the rules, by name.  Rules set to LintOff do not run.
+	Levels map[string]LintLevel
+
+	// Date is the date whose year the copyright must include.  If it is zero, the build date that templates use is
+	// taken, as given by BuildDate.
+	Date time.Time
+}
+
+// Lint checks the version information in the set against the rules, for resources that are written to the given
+// target file, which must exist.  The findings are returned in the order of the rules.
+func (set *ResourceSet) Lint(targetFileName string, options *LintOptions) ([]LintFinding, error) {
+	if options == nil {
+		options = &LintOptions{}
+	}
+	date := options.Date
+	if date.IsZero() {
+		var err error
+		if date, err = BuildDate(); err != nil {
+			return nil, err
+		}
+	}
+	context := &lintContext{TargetFileName: targetFileName, Year: date.Year()}
+	isDLL, err := IsDLL(targetFileName)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("failed to read executable file: %s (%s)", targetFileName, err))
+	}
+	context.IsDLL = isDLL
+	for _, res := range set.Resources {
+		if res.Type == ResourceTypeVersion {
+			if context.Info, err = DecodeVersionInfo(res.Data); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	var findings []LintFinding
+	for _, rule := range lintRules {
+		level := rule.Level
+		if override, ok := options.Levels[rule.Name]; ok {
+			level = override
+		}
+		if level == LintOff {
+			continue
+		}
+		for _, message := range rule.check(context) {
+			findings = append(findings, LintFinding{Rule: rule.Name, Level: level, Message: message})
+		}
+	}
+	return findings, nil
+}
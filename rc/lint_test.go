@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"debug/pe"
+	"encoding/binary"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLintVersion = `{
+	"language": "en-US",
+	"version": {
+		"fileVersion": "3.1.0.77",
+		"productVersion": "3.1",
+		"fileType": "VFT_APP",
+		"stringFileInfo": {
+			"companyName": "MongoDB, Inc.",
+			"fileDescription": "Hello",
+			"productName": "Hello",
+			"legalCopyright": "Copyright (C) 2024 MongoDB, Inc.",
+			"originalFilename": "hello.exe",
+			"internalName": "hello",
+			"productVersion": "v3.1-rc.2"
+		}
+	}
+}`
+
+// lintTestFiles writes an executable and a DLL for the lint rules to check, and returns their names.
+func lintTestFiles(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	exe := buildTestExecutable(t, testResources(), 0, 0)
+	dll := append([]byte(nil), exe...)
+	binary.LittleEndian.PutUint16(dll[0x56:], binary.LittleEndian.Uint16(dll[0x56:])|pe.IMAGE_FILE_DLL)
+	writeTestFiles(t, dir, map[string]string{"hello.exe": string(exe), "hello.dll": string(dll)})
+	return filepath.Join(dir, "hello.exe"), filepath.Join(dir, "hello.dll")
+}
+
+// lintTestSet parses testLintVersion with the fields named by the overrides changed.
+func lintTestSet(t *testing.T, overrides ...string) *ResourceSet {
+	jsonData, _, err := DecodeJSONC([]byte(testLintVersion))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range overrides {
+		override, err := ParseOverride(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ApplyOverrides(jsonData, nil, []Override{override}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set, err := ParseResources(jsonData, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func lintRuleNames(findings []LintFinding) []string {
+	var names []string
+	for _, finding := range findings {
+		names = append(names, finding.Rule)
+	}
+	return names
+}
+
+func TestLintRules(t *testing.T) {
+	exe, dll := lintTestFiles(t)
+	options := &LintOptions{Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+	for _, test := range []struct {
+		Target    string
+		Overrides []string
+		Rules     []string
+	}{
+		{exe, nil, nil},
+		{exe, []string{"version.stringFileInfo.companyName= "}, []string{"required-strings"}},
+		{exe, []string{"version.stringFileInfo.originalFilename=other.exe"}, []string{"original-filename"}},
+		{exe, []string{"version.stringFileInfo.originalFilename=HELLO.EXE"}, nil},
+		{exe, []string{"version.stringFileInfo.internalName=other"}, []string{"internal-name"}},
+		{exe, []string{"version.stringFileInfo.internalName=hello.exe"}, nil},
+		{exe, []string{"version.stringFileInfo.legalCopyright=Copyright (C) 2023"}, []string{"copyright-year"}},
+		{exe, []string{"version.stringFileInfo.fileVersion=3.2"}, []string{"file-version-string"}},
+		{exe, []string{"version.stringFileInfo.fileVersion=release"}, []string{"file-version-string"}},
+		{exe, []string{"version.stringFileInfo.productVersion=3.1.1"}, []string{"product-version-string"}},
+		{exe, []string{"version.fileType=VFT_DLL"}, []string{"file-type"}},
+		{exe, []string{"version.fileType=VFT_DRV"}, nil},
+		{exe, []string{"version.fileType=VFT_FONT"}, nil},
+		{exe, []string{"version.fileType=VFT_STATIC_LIB"}, []string{"file-type"}},
+		{dll, []string{"version.fileType=VFT_DLL"}, []string{"original-filename"}},
+		{dll, []string{"version.fileType=VFT_APP", "version.stringFileInfo.originalFilename=hello.dll"},
+			[]string{"file-type"}},
+	} {
+		findings, err := lintTestSet(t, test.Overrides...).Lint(test.Target, options)
+		if err != nil {
+			t.Fatalf("%s %v: %s", filepath.Base(test.Target), test.Overrides, err)
+		}
+		if rules := lintRuleNames(findings); !reflect.DeepEqual(rules, test.Rules) {
+			t.Errorf("%s %v: got findings %v, expected findings of %v", filepath.Base(test.Target), test.Overrides,
+				findings, test.Rules)
+		}
+	}
+
+	// without version information, only the rule that requires it reports anything
+	findings, err := (&ResourceSet{Language: 0x0409}).Lint(exe, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules := lintRuleNames(findings); !reflect.DeepEqual(rules, []string{"version-resource"}) {
+		t.Errorf("got findings %v without version information", findings)
+	}
+}
+
+func TestLintLevels(t *testing.T) {
+	exe, _ := lintTestFiles(t)
+	set := lintTestSet(t, "version.stringFileInfo.companyName= ", "version.stringFileInfo.internalName=other")
+	findings, err := set.Lint(exe, &LintOptions{
+		Levels: map[string]LintLevel{"required-strings": LintError, "internal-name": LintOff},
+		Date:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Rule != "required-strings" || findings[0].Level != LintError {
+		t.Errorf("got findings %v, expected one required-strings error", findings)
+	}
+
+	// changing the rules that LintRules returns does not change the rules that run
+	for _, rule := range LintRules() {
+		rule.Level = LintOff
+	}
+	if rules := LintRules(); rules[0].Level == LintOff {
+		t.Errorf("changing the rules returned by LintRules turned %s off", rules[0].Name)
+	}
+}
+
+// TestLintCopyrightYearUsesBuildDate checks that without a date in the options, the copyright must include the year
+// of SOURCE_DATE_EPOCH, as the templates do.
+func TestLintCopyrightYearUsesBuildDate(t *testing.T) {
+	exe, _ := lintTestFiles(t)
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000") // November 2023
+	findings, err := lintTestSet(t).Lint(exe, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Rule != "copyright-year" || !strings.Contains(findings[0].Message, "2023") {
+		t.Errorf("got findings %v, expected the copyright to lack the year 2023", findings)
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "soon")
+	if _, err := lintTestSet(t).Lint(exe, nil); err == nil {
+		t.Errorf("an invalid SOURCE_DATE_EPOCH was accepted")
+	}
+}
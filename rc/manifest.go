@@ -0,0 +1,276 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	asmV1Namespace          = "urn:schemas-microsoft-com:asm.v1"
+	asmV3Namespace          = "urn:schemas-microsoft-com:asm.v3"
+	compatibilityNamespace  = "urn:schemas-microsoft-com:compatibility.v1"
+	windowsSettings2005     = "http://schemas.microsoft.com/SMI/2005/WindowsSettings"
+	windowsSettings2016     = "http://schemas.microsoft.com/SMI/2016/WindowsSettings"
+	windowsSettings2019     = "http://schemas.microsoft.com/SMI/2019/WindowsSettings"
+	windowsSettings2020     = "http://schemas.microsoft.com/SMI/2020/WindowsSettings"
+	commonControlsName      = "Microsoft.Windows.Common-Controls"
+	commonControlsVersion   = "6.0.0.0"
+	commonControlsPublicKey = "6595b64144ccf1df"
+)
+
+// Manifest resource IDs, as defined in the Win32 header winuser.h.
+const (
+	CREATEPROCESS_MANIFEST_RESOURCE_ID                 = 1
+	ISOLATIONAWARE_MANIFEST_RESOURCE_ID                = 2
+	ISOLATIONAWARE_NOSTATICIMPORT_MANIFEST_RESOURCE_ID = 3
+)
+
+type supportedOSName struct {
+	Names []string
+	Id    string
+}
+
+// Operating systems that may be listed in the supportedOS field, with the friendly names accepted for each.  Names
+// are compared case-insensitively after removing any "Windows" prefix and spaces.
+var supportedOSNames = []supportedOSName{
+	{Names: []string{"vista"},    Id: "{e2011457-1546-43c5-a5fe-008deee3d3f0}"},
+	{Names: []string{"7"},        Id: "{35138b9a-5d96-4fbd-8e2d-a2440225f93a}"},
+	{Names: []string{"8"},        Id: "{4a2f28e3-53b9-4441-ba9c-d69d4a4a6e38}"},
+	{Names: []string{"8.1"},      Id: "{1f676c76-80e1-4239-95bb-83d0f6d0da78}"},
+	{Names: []string{"10", "11"}, Id: "{8e0f7a12-bfb3-4fe8-b9a5-48fd50a15a9a}"},
+}
+
+func LookupSupportedOS(name string) (string, bool) {
+	normalized := strings.ToLower(strings.Replace(name, " ", "", -1))
+	normalized = strings.TrimPrefix(strings.TrimPrefix(normalized, "windows"), "win")
+	for _, os := range supportedOSNames {
+		for _, osName := range os.Names {
+			if normalized == osName {
+				return os.Id, true
+			}
+		}
+	}
+	return "", false
+}
+
+type AssemblyIdentity struct {
+	Name                  string
+	Version               string
+	ProcessorArchitecture string
+}
+
+// Manifest describes the settings from which an application manifest is generated.  Empty fields are omitted.
+type Manifest struct {
+	Identity                *AssemblyIdentity
+	RequestedExecutionLevel string
+	UIAccess                bool
+	DPIAware                string
+	DPIAwareness            string
+	LongPathAware           bool
+	ActiveCodePage          string
+	SupportedOS             []string
+	CommonControls          bool
+	HeapType                string
+}
+
+type manifestWriter struct {
+	buf    bytes.Buffer
+	indent int
+}
+
+func (w *manifestWriter) line(format string, args ...interface{}) {
+	w.buf.WriteString(strings.Repeat("  ", w.indent))
+	fmt.Fprintf(&w.buf, format, args...)
+	w.buf.WriteString("\r\n")
+}
+
+func (w *manifestWriter) open(format string, args ...interface{}) {
+	w.line(format, args...)
+	w.indent++
+}
+
+func (w *manifestWriter) close(tag string) {
+	w.indent--
+	w.line("</%s>", tag)
+}
+
+func (w *manifestWriter) setting(name string, namespace string, value string) {
+	w.line("<%s xmlns=\"%s\">%s</%s>", name, namespace, escapeXML(value), name)
+}
+
+func escapeXML(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+func EncodeManifest(manifest *Manifest) []byte {
+	var w manifestWriter
+	w.line("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>")
+	w.open("<assembly xmlns=\"%s\" manifestVersion=\"1.0\">", asmV1Namespace)
+	if manifest.Identity != nil {
+		architecture := ""
+		if manifest.Identity.ProcessorArchitecture != "" {
+			architecture = fmt.Sprintf(" processorArchitecture=\"%s\"",
+				escapeXML(manifest.Identity.ProcessorArchitecture))
+		}
+		w.line("<assemblyIdentity type=\"win32\" name=\"%s\" version=\"%s\"%s/>",
+			escapeXML(manifest.Identity.Name), escapeXML(manifest.Identity.Version), architecture)
+	}
+	if manifest.RequestedExecutionLevel != "" {
+		w.open("<trustInfo xmlns=\"%s\">", asmV3Namespace)
+		w.open("<security>")
+		w.open("<requestedPrivileges>")
+		w.line("<requestedExecutionLevel level=\"%s\" uiAccess=\"%t\"/>",
+			escapeXML(manifest.RequestedExecutionLevel), manifest.UIAccess)
+		w.close("requestedPrivileges")
+		w.close("security")
+		w.close("trustInfo")
+	}
+	if len(manifest.SupportedOS) > 0 {
+		w.open("<compatibility xmlns=\"%s\">", compatibilityNamespace)
+		w.open("<application>")
+		for _, id := range manifest.SupportedOS {
+			w.line("<supportedOS Id=\"%s\"/>", escapeXML(id))
+		}
+		w.close("application")
+		w.close("compatibility")
+	}
+	if manifest.DPIAware != "" || manifest.DPIAwareness != "" || manifest.LongPathAware ||
+		manifest.ActiveCodePage != "" || manifest.HeapType != "" {
+		w.open("<application xmlns=\"%s\">", asmV3Namespace)
+		w.open("<windowsSettings>")
+		if manifest.DPIAware != "" {
+			w.setting("dpiAware", windowsSettings2005, manifest.DPIAware)
+		}
+		if manifest.DPIAwareness != "" {
+			w.setting("dpiAwareness", windowsSettings2016, manifest.DPIAwareness)
+		}
+		if manifest.LongPathAware {
+			w.setting("longPathAware", windowsSettings2016, "true")
+		}
+		if manifest.ActiveCodePage != "" {
+			w.setting("activeCodePage", windowsSettings2019, manifest.ActiveCodePage)
+		}
+		if manifest.HeapType != "" {
+			w.setting("heapType", windowsSettings2020, manifest.HeapType)
+		}
+		w.close("windowsSettings")
+		w.close("application")
+	}
+	if manifest.CommonControls {
+		w.open("<dependency>")
+		w.open("<dependentAssembly>")
+		w.line("<assemblyIdentity type=\"win32\" name=\"%s\" version=\"%s\" processorArchitecture=\"*\" "+
+			"publicKeyToken=\"%s\" language=\"*\"/>",
+			commonControlsName, commonControlsVersion, commonControlsPublicKey)
+		w.close("dependentAssembly")
+		w.close("dependency")
+	}
+	w.close("assembly")
+	return w.buf.Bytes()
+}
+
+var (
+	manifestNamespaces = []string{
+		asmV1Namespace,
+		"urn:schemas-microsoft-com:asm.v2",
+		asmV3Namespace,
+		compatibilityNamespace,
+	}
+	windowsSettingsPrefix = "http://schemas.microsoft.com/SMI/"
+	windowsSettingsSuffix = "/WindowsSettings"
+)
+
+func isManifestNamespace(namespace string) bool {
+	for _, knownNamespace := range manifestNamespaces {
+		if namespace == knownNamespace {
+			return true
+		}
+	}
+	return strings.HasPrefix(namespace, windowsSettingsPrefix) && strings.HasSuffix(namespace, windowsSettingsSuffix)
+}
+
+func manifestAttr(element xml.StartElement, name string) (string, bool) {
+	for _, attr := range element.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// ValidateManifest checks that the data is a well-formed assembly manifest whose elements all belong to the
+// namespaces of the Windows side-by-side assembly schema.  Errors are reported with the line number at which they
+// were found.
+func ValidateManifest(data []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	sawRoot := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			line, _ := decoder.InputPos()
+			if depth == 0 {
+				if sawRoot {
+					return errors.New(fmt.Sprintf("line %d: manifest contains more than one root element", line))
+				}
+				if element.Name.Space != asmV1Namespace || element.Name.Local != "assembly" {
+					return errors.New(fmt.Sprintf("line %d: root element must be assembly in namespace %s",
+						line, asmV1Namespace))
+				}
+				if version, _ := manifestAttr(element, "manifestVersion"); version != "1.0" {
+					return errors.New(fmt.Sprintf("line %d: assembly must specify manifestVersion=\"1.0\"", line))
+				}
+				sawRoot = true
+			} else if !isManifestNamespace(element.Name.Space) {
+				return errors.New(fmt.Sprintf("line %d: element %s is in unknown namespace \"%s\"",
+					line, element.Name.Local, element.Name.Space))
+			}
+			if element.Name.Local == "requestedExecutionLevel" {
+				level, _ := manifestAttr(element, "level")
+				switch level {
+				case "asInvoker", "highestAvailable", "requireAdministrator":
+				default:
+					return errors.New(fmt.Sprintf("line %d: invalid requestedExecutionLevel: %s", line, level))
+				}
+				if uiAccess, ok := manifestAttr(element, "uiAccess"); ok && uiAccess != "true" && uiAccess != "false" {
+					return errors.New(fmt.Sprintf("line %d: invalid uiAccess: %s", line, uiAccess))
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	if !sawRoot {
+		return errors.New("manifest does not contain an assembly element")
+	}
+	return nil
+}
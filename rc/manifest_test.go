@@ -0,0 +1,223 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"debug/pe"
+	"encoding/binary"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeManifest(t *testing.T) {
+	for _, test := range []struct {
+		Name  string
+		Json  string
+		Lines []string
+	}{
+		{
+			Name: "empty",
+			Json: `{}`,
+			Lines: []string{
+				`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`,
+				`<assembly xmlns="urn:schemas-microsoft-com:asm.v1" manifestVersion="1.0">`,
+				`</assembly>`,
+			},
+		},
+		{
+			Name: "uiAccess implies asInvoker",
+			Json: `{"assemblyIdentity": {"name": "A&B", "version": "1.0.0.0"}, "uiAccess": true}`,
+			Lines: []string{
+				`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`,
+				`<assembly xmlns="urn:schemas-microsoft-com:asm.v1" manifestVersion="1.0">`,
+				`  <assemblyIdentity type="win32" name="A&amp;B" version="1.0.0.0"/>`,
+				`  <trustInfo xmlns="urn:schemas-microsoft-com:asm.v3">`,
+				`    <security>`,
+				`      <requestedPrivileges>`,
+				`        <requestedExecutionLevel level="asInvoker" uiAccess="true"/>`,
+				`      </requestedPrivileges>`,
+				`    </security>`,
+				`  </trustInfo>`,
+				`</assembly>`,
+			},
+		},
+		{
+			Name: "every setting",
+			Json: `{
+				"assemblyIdentity": {"name": "MongoDB.Hello", "version": "1.2.3.4", "processorArchitecture": "AMD64"},
+				"requestedExecutionLevel": "requireadministrator",
+				"dpiAware": "true/pm",
+				"dpiAwareness": ["PerMonitorV2", "perMonitor"],
+				"longPathAware": true,
+				"activeCodePage": "utf-8",
+				"supportedOS": ["Windows 7", "win10", "11", "8.1"],
+				"commonControls": true,
+				"heapType": "SegmentHeap"
+			}`,
+			Lines: []string{
+				`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`,
+				`<assembly xmlns="urn:schemas-microsoft-com:asm.v1" manifestVersion="1.0">`,
+				`  <assemblyIdentity type="win32" name="MongoDB.Hello" version="1.2.3.4" processorArchitecture="amd64"/>`,
+				`  <trustInfo xmlns="urn:schemas-microsoft-com:asm.v3">`,
+				`    <security>`,
+				`      <requestedPrivileges>`,
+				`        <requestedExecutionLevel level="requireAdministrator" uiAccess="false"/>`,
+				`      </requestedPrivileges>`,
+				`    </security>`,
+				`  </trustInfo>`,
+				`  <compatibility xmlns="urn:schemas-microsoft-com:compatibility.v1">`,
+				`    <application>`,
+				`      <supportedOS Id="{35138b9a-5d96-4fbd-8e2d-a2440225f93a}"/>`,
+				`      <supportedOS Id="{8e0f7a12-bfb3-4fe8-b9a5-48fd50a15a9a}"/>`,
+				`      <supportedOS Id="{1f676c76-80e1-4239-95bb-83d0f6d0da78}"/>`,
+				`    </application>`,
+				`  </compatibility>`,
+				`  <application xmlns="urn:schemas-microsoft-com:asm.v3">`,
+				`    <windowsSettings>`,
+				`      <dpiAware xmlns="http://schemas.microsoft.com/SMI/2005/WindowsSettings">true/pm</dpiAware>`,
+				`      <dpiAwareness xmlns="http://schemas.microsoft.com/SMI/2016/WindowsSettings">` +
+					`PerMonitorV2, PerMonitor</dpiAwareness>`,
+				`      <longPathAware xmlns="http://schemas.microsoft.com/SMI/2016/WindowsSettings">true</longPathAware>`,
+				`      <activeCodePage xmlns="http://schemas.microsoft.com/SMI/2019/WindowsSettings">UTF-8</activeCodePage>`,
+				`      <heapType xmlns="http://schemas.microsoft.com/SMI/2020/WindowsSettings">SegmentHeap</heapType>`,
+				`    </windowsSettings>`,
+				`  </application>`,
+				`  <dependency>`,
+				`    <dependentAssembly>`,
+				`      <assemblyIdentity type="win32" name="Microsoft.Windows.Common-Controls" version="6.0.0.0" ` +
+					`processorArchitecture="*" publicKeyToken="6595b64144ccf1df" language="*"/>`,
+				`    </dependentAssembly>`,
+				`  </dependency>`,
+				`</assembly>`,
+			},
+		},
+	} {
+		data, err := parseTestResources(t, `{"manifest": `+test.Json+`}`, ResourceTypeManifest)
+		if err != nil {
+			t.Errorf("%s: %s", test.Name, err)
+			continue
+		}
+		expected := strings.Join(test.Lines, "\r\n") + "\r\n"
+		if len(data) != 1 || string(data[0]) != expected {
+			t.Errorf("%s: got\n%s\nexpected\n%s", test.Name, data, expected)
+		} else if err := ValidateManifest(data[0]); err != nil {
+			t.Errorf("%s: the generated manifest is invalid: %s", test.Name, err)
+		}
+	}
+}
+
+func TestParseManifestErrors(t *testing.T) {
+	for _, test := range []struct {
+		Json  string
+		Paths []string
+	}{
+		{`{"assemblyIdentity": {"version": "1.2.x", "processorArchitecture": "mips"}}`,
+			[]string{"/manifest/assemblyIdentity/name", "/manifest/assemblyIdentity/processorArchitecture",
+				"/manifest/assemblyIdentity/version"}},
+		{`{"requestedExecutionLevel": "root", "uiAccess": "yes", "dpiAware": 1}`,
+			[]string{"/manifest/dpiAware", "/manifest/requestedExecutionLevel", "/manifest/uiAccess"}},
+		{`{"supportedOS": ["XP", "10"], "dpiAwareness": ["System", "Sometimes"], "heapType": "NT"}`,
+			[]string{"/manifest/dpiAwareness", "/manifest/heapType", "/manifest/supportedOS/0"}},
+		{`{"dpiAwarness": "System"}`, []string{"/manifest/dpiAwarness"}},
+		{`{"file": "app.manifest", "uiAccess": true}`, []string{"/manifest/file"}},
+	} {
+		_, err := parseTestResources(t, `{"manifest": `+test.Json+`}`, ResourceTypeManifest)
+		if err == nil {
+			t.Errorf("%s: no error", test.Json)
+		} else if paths := errorPaths(err); !reflect.DeepEqual(paths, test.Paths) {
+			t.Errorf("%s: errors at %q, expected %q (%s)", test.Json, paths, test.Paths, err)
+		}
+	}
+}
+
+func TestValidateManifest(t *testing.T) {
+	const header = "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"
+	const assembly = `<assembly xmlns="urn:schemas-microsoft-com:asm.v1" manifestVersion="1.0">` + "\n"
+	for _, test := range []struct {
+		Manifest string
+		Error    string
+	}{
+		{header + assembly + "</assembly>", ""},
+		{testManifest, ""},
+		{header + assembly + `<application xmlns="http://schemas.microsoft.com/SMI/2024/WindowsSettings"/>` + "\n" +
+			"</assembly>", ""},
+		{header + "<assembly manifestVersion=\"1.0\"/>", "line 2: root element must be assembly"},
+		{header + `<assembly xmlns="urn:schemas-microsoft-com:asm.v1"/>`, "line 2: assembly must specify manifestVersion"},
+		{header + assembly + "<trustInfo xmlns=\"urn:example\"/>\n</assembly>", "line 3: element trustInfo is in unknown"},
+		{header + assembly + "\n<requestedExecutionLevel xmlns=\"urn:schemas-microsoft-com:asm.v3\" level=\"root\"/>" +
+			"</assembly>", "line 4: invalid requestedExecutionLevel: root"},
+		{header + assembly + "<requestedExecutionLevel xmlns=\"urn:schemas-microsoft-com:asm.v3\" level=\"asInvoker\" " +
+			"uiAccess=\"yes\"/></assembly>", "line 3: invalid uiAccess: yes"},
+		{header + assembly + "</assembly>\n" + assembly + "</assembly>", "line 4: manifest contains more than one root"},
+		{header + assembly + "<unclosed>", "unexpected EOF"},
+		{header, "manifest does not contain an assembly element"},
+	} {
+		err := ValidateManifest([]byte(test.Manifest))
+		if test.Error == "" && err != nil {
+			t.Errorf("%q: %s", test.Manifest, err)
+		} else if test.Error != "" && (err == nil || !strings.Contains(err.Error(), test.Error)) {
+			t.Errorf("%q: got error %v, expected %q", test.Manifest, err, test.Error)
+		}
+	}
+}
+
+func TestManifestResourceId(t *testing.T) {
+	dir := t.TempDir()
+	exe := buildTestExecutable(t, testResources(), 0, 0)
+	dll := append([]byte(nil), exe...)
+	// set IMAGE_FILE_DLL in the characteristics of the file header, which follows the signature at 0x40
+	binary.LittleEndian.PutUint16(dll[0x56:], binary.LittleEndian.Uint16(dll[0x56:])|pe.IMAGE_FILE_DLL)
+	writeTestFiles(t, dir, map[string]string{"app.exe": string(exe), "lib.dll": string(dll)})
+	for _, test := range []struct {
+		Target   string
+		Manifest string
+		Id       uint
+	}{
+		{"", `{}`, CREATEPROCESS_MANIFEST_RESOURCE_ID},
+		{"app.exe", `{}`, CREATEPROCESS_MANIFEST_RESOURCE_ID},
+		{"lib.dll", `{}`, ISOLATIONAWARE_MANIFEST_RESOURCE_ID},
+		{"lib.dll", `{"resourceId": 3}`, ISOLATIONAWARE_NOSTATICIMPORT_MANIFEST_RESOURCE_ID},
+		{"app.exe", `{"resourceId": 2}`, ISOLATIONAWARE_MANIFEST_RESOURCE_ID},
+	} {
+		jsonData, _, err := DecodeJSONC([]byte(`{"manifest": ` + test.Manifest + `}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		target := ""
+		if test.Target != "" {
+			target = filepath.Join(dir, test.Target)
+		}
+		set, err := ParseResources(jsonData, dir, target)
+		if err != nil {
+			t.Errorf("%s %s: %s", test.Target, test.Manifest, err)
+		} else if len(set.Resources) != 1 || set.Resources[0].Id != test.Id {
+			t.Errorf("%s %s: got %v, expected a manifest with ID %d", test.Target, test.Manifest, set.Resources,
+				test.Id)
+		}
+	}
+	for _, manifest := range []string{`{"resourceId": 0}`, `{"resourceId": 4}`} {
+		if _, err := parseTestResources(t, `{"manifest": `+manifest+`}`, ResourceTypeManifest); err == nil {
+			t.Errorf("%s: no error", manifest)
+		}
+	}
+	jsonData, _, _ := DecodeJSONC([]byte(`{"manifest": {}}`))
+	if _, err := ParseResources(jsonData, dir, filepath.Join(dir, "missing.exe")); err == nil {
+		t.Error("a missing target was accepted")
+	}
+}
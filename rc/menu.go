@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	MF_GRAYED       = 0x0001
+	MF_DISABLED     = 0x0002
+	MF_CHECKED      = 0x0008
+	MF_POPUP        = 0x0010
+	MF_MENUBARBREAK = 0x0020
+	MF_MENUBREAK    = 0x0040
+	MF_END          = 0x0080
+	MF_HELP         = 0x4000
+
+	MFT_MENUBARBREAK = 0x00000020
+	MFT_MENUBREAK    = 0x00000040
+	MFT_RADIOCHECK   = 0x00000200
+	MFT_SEPARATOR    = 0x00000800
+	MFT_RIGHTJUSTIFY = 0x00004000
+
+	MFS_GRAYED  = 0x00000003
+	MFS_CHECKED = 0x00000008
+	MFS_DEFAULT = 0x00001000
+
+	menuExItemPopup = 0x01
+	menuExItemEnd   = 0x80
+)
+
+type MenuItem struct {
+	Text         string
+	Id           uint32
+	HelpId       uint32
+	Separator    bool
+	Checked      bool
+	Grayed       bool
+	Disabled     bool
+	Default      bool
+	RadioCheck   bool
+	RightJustify bool
+	MenuBreak    bool
+	MenuBarBreak bool
+	Popup        bool
+	Items        []MenuItem
+}
+
+type Menu struct {
+	HelpId uint32
+	Items  []MenuItem
+}
+
+func encodeMenuExItems(buf *bytes.Buffer, items []MenuItem) {
+	for i, item := range items {
+		var itemType, itemState uint32
+		var resInfo uint16
+		if item.Separator {
+			itemType |= MFT_SEPARATOR
+		}
+		if item.RadioCheck {
+			itemType |= MFT_RADIOCHECK
+		}
+		if item.RightJustify {
+			itemType |= MFT_RIGHTJUSTIFY
+		}
+		if item.MenuBreak {
+			itemType |= MFT_MENUBREAK
+		}
+		if item.MenuBarBreak {
+			itemType |= MFT_MENUBARBREAK
+		}
+		if item.Checked {
+			itemState |= MFS_CHECKED
+		}
+		if item.Grayed || item.Disabled {
+			itemState |= MFS_GRAYED
+		}
+		if item.Default {
+			itemState |= MFS_DEFAULT
+		}
+		if item.Popup {
+			resInfo |= menuExItemPopup
+		}
+		if i == len(items)-1 {
+			resInfo |= menuExItemEnd
+		}
+		binary.Write(buf, binary.LittleEndian, itemType)
+		binary.Write(buf, binary.LittleEndian, itemState)
+		binary.Write(buf, binary.LittleEndian, item.Id)
+		binary.Write(buf, binary.LittleEndian, resInfo)
+		writeUTF16String(buf, item.Text)
+		alignBuffer(buf, 4)
+		if item.Popup {
+			binary.Write(buf, binary.LittleEndian, item.HelpId)
+			encodeMenuExItems(buf, item.Items)
+		}
+	}
+}
+
+// EncodeMenuEx encodes a menu in the MENUEX template format used by the MENUEX statement in .rc files.
+func EncodeMenuEx(menu *Menu) []byte {
+	var buf bytes.Buffer
+	// MENUEX_TEMPLATE_HEADER: version 1, followed by the offset from the end of this field to the first item
+	binary.Write(&buf, binary.LittleEndian, uint16(1))
+	binary.Write(&buf, binary.LittleEndian, uint16(4))
+	binary.Write(&buf, binary.LittleEndian, menu.HelpId)
+	encodeMenuExItems(&buf, menu.Items)
+	return buf.Bytes()
+}
+
+func encodeMenuItems(buf *bytes.Buffer, items []MenuItem) {
+	for i, item := range items {
+		var flags uint16
+		if item.Checked {
+			flags |= MF_CHECKED
+		}
+		if item.Grayed {
+			flags |= MF_GRAYED
+		}
+		if item.Disabled {
+			flags |= MF_DISABLED
+		}
+		if item.RightJustify {
+			flags |= MF_HELP
+		}
+		if item.MenuBreak {
+			flags |= MF_MENUBREAK
+		}
+		if item.MenuBarBreak {
+			flags |= MF_MENUBARBREAK
+		}
+		if item.Popup {
+			flags |= MF_POPUP
+		}
+		if i == len(items)-1 {
+			flags |= MF_END
+		}
+		binary.Write(buf, binary.LittleEndian, flags)
+		if item.Popup {
+			writeUTF16String(buf, item.Text)
+			encodeMenuItems(buf, item.Items)
+		} else {
+			binary.Write(buf, binary.LittleEndian, uint16(item.Id))
+			writeUTF16String(buf, item.Text)
+		}
+	}
+}
+
+// EncodeMenu encodes a menu in the legacy MENU template format.  Help IDs, default items and radio check marks cannot
+// be represented in this format and are ignored.
+func EncodeMenu(menu *Menu) []byte {
+	var buf bytes.Buffer
+	// MENUHEADER: version 0 and no extra header bytes
+	binary.Write(&buf, binary.LittleEndian, uint16(0))
+	binary.Write(&buf, binary.LittleEndian, uint16(0))
+	encodeMenuItems(&buf, menu.Items)
+	return buf.Bytes()
+}
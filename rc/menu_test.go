@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeMenu(t *testing.T) {
+	for _, test := range []struct {
+		Name string
+		Json string
+		Data string
+	}{
+		{
+			// each item and each popup help ID begins on a DWORD boundary, and the last item at each level is marked
+			Name: "menuex",
+			Json: `{"id": 1, "helpId": 9, "items": [
+				{"text": "&File", "helpId": 5, "items": [
+					{"text": "E", "id": 258, "default": true},
+					{"separator": true}
+				]},
+				{"text": "H", "id": 3, "checked": true, "rightJustify": true}
+			]}`,
+			Data: `0100 0400 | 09000000
+				00000000 | 00000000 | 00000000 | 0100 | 2600 4600 6900 6C00 6500 0000 | 0000 | 05000000
+				00000000 | 00100000 | 02010000 | 0000 | 4500 0000 | 0000
+				00080000 | 00000000 | 00000000 | 8000 | 0000
+				00400000 | 08000000 | 03000000 | 8000 | 4800 0000 | 0000`,
+		},
+		{
+			Name: "menu",
+			Json: `{"id": 1, "format": "menu", "items": [
+				{"text": "F", "items": [
+					{"text": "O", "id": 1, "grayed": true},
+					{"separator": true},
+					{"text": "X", "id": 2, "menuBreak": true}
+				]},
+				{"text": "?", "id": 3, "rightJustify": true, "disabled": true}
+			]}`,
+			Data: `0000 0000
+				1000 | 4600 0000
+				0100 | 0100 | 4F00 0000
+				0000 | 0000 | 0000
+				C000 | 0200 | 5800 0000
+				8240 | 0300 | 3F00 0000`,
+		},
+	} {
+		data, err := parseTestResources(t, `{"menus": [`+test.Json+`]}`, ResourceTypeMenu)
+		if err != nil {
+			t.Errorf("%s: %s", test.Name, err)
+		} else if expected := hexData(t, test.Data); len(data) != 1 || !bytes.Equal(data[0], expected) {
+			t.Errorf("%s: got % X, expected % X", test.Name, data, expected)
+		}
+	}
+}
+
+func TestParseMenuErrors(t *testing.T) {
+	for _, test := range []struct {
+		Json  string
+		Paths []string
+	}{
+		{`{"id": 1, "format": "menu", "items": [{"text": "A", "default": true, "radioCheck": true}]}`,
+			[]string{"/menus/0/items/0/default", "/menus/0/items/0/radioCheck"}},
+		{`{"id": 1, "format": "menu", "helpId": 1, "items": [{"text": "A", "id": 65536}]}`,
+			[]string{"/menus/0/helpId", "/menus/0/items/0/id"}},
+		{`{"id": 1, "items": [{"text": "A", "helpId": 1}, {"separator": true, "items": []}]}`,
+			[]string{"/menus/0/items/0/helpId", "/menus/0/items/1/items"}},
+		{`{"id": 1, "format": "popup", "items": [{"id": 1}]}`, []string{"/menus/0/format", "/menus/0/items/0/text"}},
+	} {
+		_, err := parseTestResources(t, `{"menus": [`+test.Json+`]}`, ResourceTypeMenu)
+		if err == nil {
+			t.Errorf("%s: no error", test.Json)
+		} else if paths := errorPaths(err); !reflect.DeepEqual(paths, test.Paths) {
+			t.Errorf("%s: errors at %q, expected %q (%s)", test.Json, paths, test.Paths, err)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// How the elements of a list in an overlay are combined with the list in the base file.  Lists that are not listed
+// here are replaced by the overlay.
+const (
+	// elements whose id matches an element of the base list replace it, and the others are appended
+	mergeById = iota + 1
+	// elements are appended, and an element with the same id and severity as one in the base list is an error
+	mergeAppendUnique
+)
+
+var listMergeRules = map[string]int{
+	"/messageTable": mergeAppendUnique,
+	"/dialogs":      mergeById,
+	"/menus":        mergeById,
+	"/accelerators": mergeById,
+	"/fonts":        mergeById,
+	"/stringTable":  mergeById,
+	"/rcdata":       mergeById,
+}
+
+// copySourceMap copies the positions of the value at srcPointer and its descendants in src to dstPointer in dst,
+// replacing any positions recorded there before.
+func copySourceMap(dst SourceMap, dstPointer string, src SourceMap, srcPointer string) {
+	for pointer := range dst {
+		if pointer == dstPointer || strings.HasPrefix(pointer, dstPointer+"/") {
+			delete(dst, pointer)
+		}
+	}
+	for pointer, pos := range src {
+		if pointer == srcPointer || strings.HasPrefix(pointer, srcPointer+"/") {
+			dst[dstPointer+pointer[len(srcPointer):]] = pos
+		}
+	}
+}
+
+// mergeListKey returns the key by which an element of a list is matched against the base list, or false if the
+// element has no usable key.
+func mergeListKey(elem interface{}, rule int) (string, bool) {
+	elemJson, ok := elem.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	id, ok := elemJson["id"].(int64)
+	if !ok {
+		// RCDATA resources may be named rather than numbered
+		if name, ok := elemJson["name"].(string); ok && rule == mergeById {
+			return "name/" + strings.ToUpper(name), true
+		}
+		return "", false
+	}
+	if rule == mergeAppendUnique {
+		severity, _ := elemJson["severity"].(string)
+		return fmt.Sprintf("%d/%s", id, severity), true
+	}
+	return fmt.Sprintf("%d", id), true
+}
+
+// checkListDuplicates reports elements of the merged lists of a single resource file whose key is the same as that of
+// an earlier element of the same list.  Within one file such an element would otherwise silently take the place of its
+// twin when both replace the same element of a base file.
+func checkListDuplicates(jsonData map[string]interface{}, sourceMap SourceMap) error {
+	var errs ErrorList
+	listPointers := make([]string, 0, len(listMergeRules))
+	for listPointer := range listMergeRules {
+		listPointers = append(listPointers, listPointer)
+	}
+	sort.Strings(listPointers)
+	for _, listPointer := range listPointers {
+		rule := listMergeRules[listPointer]
+		listJson, _ := jsonData[listPointer[1:]].([]interface{})
+		indexes := make(map[string]int)
+		for j, elem := range listJson {
+			key, ok := mergeListKey(elem, rule)
+			if !ok {
+				continue
+			}
+			first, seen := indexes[key]
+			if !seen {
+				indexes[key] = j
+				continue
+			}
+			where := ""
+			if pos, ok := sourceMap.Lookup(fmt.Sprintf("%s/%d", listPointer, first)); ok {
+				where = fmt.Sprintf(" (first at %s)", pos)
+			}
+			elemJson := elem.(map[string]interface{})
+			var err error
+			if id, ok := elemJson["id"]; !ok {
+				err = atField("name", errors.New(fmt.Sprintf("name %s is given twice in the same file%s",
+					elemJson["name"], where)))
+			} else if severity, _ := elemJson["severity"].(string); rule == mergeAppendUnique && severity != "" {
+				err = atField("id", errors.New(fmt.Sprintf(
+					"message with ID %d and severity %s is given twice in the same file%s", id, severity, where)))
+			} else {
+				err = atField("id", errors.New(fmt.Sprintf("ID %d is given twice in the same file%s", id, where)))
+			}
+			errs.add(withPath(err, fmt.Sprintf("%s/%d", listPointer, j)))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func mergeList(
+	base []interface{},
+	overlay []interface{},
+	rule int,
+	pointer string,
+	sourceMap SourceMap,
+	overlayMap SourceMap,
+	overlayPointer string) ([]interface{}, error) {
+	result := append([]interface{}(nil), base...)
+	indexes := make(map[string]int)
+	for i, elem := range base {
+		if key, ok := mergeListKey(elem, rule); ok {
+			indexes[key] = i
+		}
+	}
+	var errs ErrorList
+	for j, elem := range overlay {
+		elemPointer := fmt.Sprintf("%s/%d", overlayPointer, j)
+		key, ok := mergeListKey(elem, rule)
+		i, found := indexes[key]
+		conflict := ok && found && rule == mergeAppendUnique
+		if conflict || !ok || !found {
+			i = len(result)
+			result = append(result, nil)
+		}
+		result[i] = elem
+		copySourceMap(sourceMap, fmt.Sprintf("%s/%d", pointer, i), overlayMap, elemPointer)
+		if conflict {
+			where := "in a base file"
+			if basePos, ok := sourceMap.Lookup(fmt.Sprintf("%s/%d", pointer, indexes[key])); ok && basePos.File != "" {
+				where = fmt.Sprintf("at %s:%s", basePos.File, basePos)
+			}
+			err := errors.New(fmt.Sprintf("message with ID %d is already defined %s",
+				elem.(map[string]interface{})["id"], where))
+			errs.add(withPath(atField("id", err), fmt.Sprintf("%s/%d", pointer, i)))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return result, nil
+}
+
+func mergeObject(
+	base map[string]interface{},
+	overlay map[string]interface{},
+	pointer string,
+	sourceMap SourceMap,
+	overlayMap SourceMap,
+	overlayPointer string) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		result[key] = value
+	}
+	var errs ErrorList
+	for key, value := range overlay {
+		keyPointer := pointer + "/" + escapeJSONPointer(key)
+		overlayKeyPointer := overlayPointer + "/" + escapeJSONPointer(key)
+		switch baseValue := result[key].(type) {
+		case map[string]interface{}:
+			if overlayValue, ok := value.(map[string]interface{}); ok {
+				merged, err := mergeObject(baseValue, overlayValue, keyPointer, sourceMap, overlayMap,
+					overlayKeyPointer)
+				if err != nil {
+					errs.add(err)
+				} else {
+					result[key] = merged
+				}
+				continue
+			}
+		case []interface{}:
+			if overlayValue, ok := value.([]interface{}); ok && listMergeRules[keyPointer] != 0 {
+				merged, err := mergeList(baseValue, overlayValue, listMergeRules[keyPointer], keyPointer, sourceMap,
+					overlayMap, overlayKeyPointer)
+				if err != nil {
+					errs.add(err)
+				} else {
+					result[key] = merged
+				}
+				continue
+			}
+		}
+		result[key] = value
+		copySourceMap(sourceMap, keyPointer, overlayMap, overlayKeyPointer)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return result, nil
+}
+
+// MergeResourceData merges an overlay resource file into a base one.  Objects are merged field by field, and other
+// values in the overlay replace those in the base, except for the resource lists: dialogs, menus, accelerator tables,
+// fonts, strings and RCDATA resources in the overlay replace those with the same ID (or name) in the base and are
+// otherwise appended, and messages are appended, with an error if the base already has a message with the same ID and
+// severity.  The source map of the
+// base is updated with the positions of the values taken from the overlay.
+func MergeResourceData(
+	base map[string]interface{},
+	sourceMap SourceMap,
+	overlay map[string]interface{},
+	overlayMap SourceMap) (map[string]interface{}, error) {
+	return mergeObject(base, overlay, "", sourceMap, overlayMap, "")
+}
+
+// rebaseFileNames makes the relative names of manifest and font files in a resource file absolute, taking them
+// relative to dir, the directory of the file.  This keeps them referring to the same files once the file is merged
+// beneath another one in a different directory.
+func rebaseFileNames(jsonData map[string]interface{}, dir string) {
+	rebase := func(obj map[string]interface{}, key string) {
+		if fileName, ok := obj[key].(string); ok && fileName != "" && !filepath.IsAbs(fileName) {
+			obj[key] = filepath.Join(dir, fileName)
+		}
+	}
+	rebase(jsonData, "manifest")
+	if manifestJson, ok := jsonData["manifest"].(map[string]interface{}); ok {
+		rebase(manifestJson, "file")
+	}
+	for _, listKey := range []string{"fonts", "rcdata"} {
+		if listJson, ok := jsonData[listKey].([]interface{}); ok {
+			for _, elemObj := range listJson {
+				if elemJson, ok := elemObj.(map[string]interface{}); ok {
+					rebase(elemJson, "file")
+				}
+			}
+		}
+	}
+}
+
+// decodeLayeredFile decodes a resource file together with the files named by its extends field, which are merged in
+// order beneath it.  The positions in the source map record the file from which each value came, and relative file
+// names in the base files are made absolute with rebaseFileNames.
+func decodeLayeredFile(fileName string, seen map[string]bool) (map[string]interface{}, SourceMap, error) {
+	absFileName, err := filepath.Abs(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if seen[absFileName] {
+		return nil, nil, errors.New(fmt.Sprintf("resource file %s extends itself", fileName))
+	}
+	seen[absFileName] = true
+	defer delete(seen, absFileName)
+
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	jsonData, sourceMap, err := DecodeResourceFile(fileName, data)
+	if syntaxErr, ok := err.(*SyntaxError); ok {
+		syntaxErr.Pos.File = fileName
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for pointer, pos := range sourceMap {
+		pos.File = fileName
+		sourceMap[pointer] = pos
+	}
+	if err := checkListDuplicates(jsonData, sourceMap); err != nil {
+		return nil, sourceMap, err
+	}
+	extendsObj, ok := jsonData["extends"]
+	if !ok {
+		return jsonData, sourceMap, nil
+	}
+	var baseNames []interface{}
+	if baseName, ok := extendsObj.(string); ok {
+		baseNames = []interface{}{baseName}
+	} else if baseNames, ok = extendsObj.([]interface{}); !ok {
+		return nil, sourceMap, fieldError("extends", "must specify a file name or a list of file names")
+	}
+	var result map[string]interface{}
+	resultMap := make(SourceMap)
+	for i, baseNameObj := range baseNames {
+		baseName, ok := baseNameObj.(string)
+		if !ok {
+			return nil, sourceMap, fieldError("extends", "must specify a file name or a list of file names")
+		}
+		if !filepath.IsAbs(baseName) {
+			baseName = filepath.Join(filepath.Dir(fileName), baseName)
+		}
+		baseData, baseMap, err := decodeLayeredFile(baseName, seen)
+		if err != nil {
+			// errors in the contents of the base file are reported at their own positions
+			if _, ok := err.(*SyntaxError); ok || baseMap != nil {
+				return nil, baseMap, err
+			}
+			err = errors.New(fmt.Sprintf("could not read base file '%s' (%s)", baseName, err))
+			return nil, sourceMap, atField("extends", withPath(err, fmt.Sprintf("/%d", i)))
+		}
+		absBaseName, err := filepath.Abs(baseName)
+		if err != nil {
+			return nil, sourceMap, err
+		}
+		rebaseFileNames(baseData, filepath.Dir(absBaseName))
+		if result == nil {
+			result, resultMap = baseData, baseMap
+		} else if result, err = MergeResourceData(result, resultMap, baseData, baseMap); err != nil {
+			return nil, resultMap, err
+		}
+	}
+	delete(jsonData, "extends")
+	if result == nil {
+		return jsonData, sourceMap, nil
+	}
+	if result, err = MergeResourceData(result, resultMap, jsonData, sourceMap); err != nil {
+		return nil, resultMap, err
+	}
+	return result, resultMap, nil
+}
+
+// DecodeResourceFiles decodes one or more resource files and merges them in order with MergeResourceData, so that
+// each file overrides the ones before it.  Each file may itself name base files in its extends field, relative to its
+// own directory.  Relative names of manifest and font files are left relative only if they come from the last file;
+// those from other files are made absolute, so that each refers to a file relative to the file that names it.
+func DecodeResourceFiles(fileNames []string) (map[string]interface{}, SourceMap, error) {
+	var result map[string]interface{}
+	resultMap := make(SourceMap)
+	for i, fileName := range fileNames {
+		jsonData, sourceMap, err := decodeLayeredFile(fileName, make(map[string]bool))
+		if err != nil {
+			return nil, sourceMap, err
+		}
+		if i < len(fileNames)-1 {
+			absFileName, err := filepath.Abs(fileName)
+			if err != nil {
+				return nil, sourceMap, err
+			}
+			rebaseFileNames(jsonData, filepath.Dir(absFileName))
+		}
+		if result == nil {
+			result, resultMap = jsonData, sourceMap
+		} else if result, err = MergeResourceData(result, resultMap, jsonData, sourceMap); err != nil {
+			return nil, resultMap, err
+		}
+	}
+	return result, resultMap, nil
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testManifest = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<assembly xmlns="urn:schemas-microsoft-com:asm.v1" manifestVersion="1.0"/>
+`
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		fileName := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fileName), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fileName, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestBaseFileNamesAreRelativeToBase checks that the file names in base files, whether named by extends or given
+// before the last file, are resolved against the directory of the file that names them.
+func TestBaseFileNamesAreRelativeToBase(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"common/base.json":     `{"manifest": {"file": "app.manifest"}, "language": "en-US"}`,
+		"common/app.manifest":  testManifest,
+		"product/product.json": `{"extends": "../common/base.json", "manifest": {"resourceId": 2}}`,
+		"product/overlay.json": `{"language": "de-DE"}`,
+	})
+	for _, fileNames := range [][]string{
+		{"product/product.json"},
+		{"common/base.json", "product/overlay.json"},
+	} {
+		for i := range fileNames {
+			fileNames[i] = filepath.Join(dir, filepath.FromSlash(fileNames[i]))
+		}
+		set, _, err := LoadResourceFiles(fileNames, nil)
+		if err != nil {
+			t.Errorf("%s: %s", fileNames, err)
+			continue
+		}
+		if len(set.Resources) != 1 || string(set.Resources[0].Data) != testManifest {
+			t.Errorf("%s: the manifest was not loaded from the base file's directory", fileNames)
+		}
+	}
+}
+
+// TestDuplicateListElementsInOneFile checks that an element given twice in the same file is reported at its own
+// position in that file, both in an overlay, where it would otherwise replace its twin in the base, and in the base.
+func TestDuplicateListElementsInOneFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"base.json": `{"stringTable": [{"id": 1, "text": "base"}]}`,
+		"overlay.json": `{
+	"stringTable": [{"id": 1, "text": "one"}, {"id": 1, "text": "two"}],
+	"rcdata": [{"name": "config", "text": "a"}, {"name": "CONFIG", "text": "b"}],
+	"messageTable": [{"id": 1, "severity": "error", "text": "a"}, {"id": 1, "severity": "warning", "text": "b"},
+		{"id": 1, "severity": "error", "text": "c"}]
+}`,
+		"dup-base.json": `{"menus": [{"id": 1}, {"id": 1}]}`,
+		"dup-top.json":  `{"extends": "dup-base.json"}`,
+		"no-dups.json":  `{"stringTable": [{"id": 1, "text": "one"}, {"id": 2, "text": "two"}]}`,
+	})
+	for _, test := range []struct {
+		fileNames []string
+		paths     []string
+		file      string
+		line      int
+	}{
+		{[]string{"base.json", "overlay.json"}, []string{"/messageTable/2/id", "/rcdata/1/name", "/stringTable/1/id"},
+			"overlay.json", 2},
+		{[]string{"dup-top.json"}, []string{"/menus/1/id"}, "dup-base.json", 1},
+		{[]string{"base.json", "no-dups.json"}, nil, "", 0},
+	} {
+		fileNames := make([]string, len(test.fileNames))
+		for i, fileName := range test.fileNames {
+			fileNames[i] = filepath.Join(dir, fileName)
+		}
+		_, sourceMap, err := DecodeResourceFiles(fileNames)
+		if err == nil || test.paths == nil {
+			if err != nil || test.paths != nil {
+				t.Errorf("%s: got errors %v, want errors at %v", test.fileNames, err, test.paths)
+			}
+			continue
+		}
+		if paths := errorPaths(err); !reflect.DeepEqual(paths, test.paths) {
+			t.Errorf("%s: got errors %v, want errors at %v", test.fileNames, err, test.paths)
+			continue
+		}
+		if !strings.Contains(err.Error(), "is given twice in the same file (first at ") {
+			t.Errorf("%s: got %s", test.fileNames, err)
+		}
+		if pos, ok := sourceMap.Lookup(test.paths[len(test.paths)-1]); !ok || filepath.Base(pos.File) != test.file ||
+			pos.Line != test.line {
+			t.Errorf("%s: the error is at %v, want %s:%d", test.fileNames, pos, test.file, test.line)
+		}
+	}
+}
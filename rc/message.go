@@ -14,16 +14,30 @@
  * limitations under the License.
  */
 
-package main
+package rc
 
 import (
-	"github.com/winlabs/gowin32/wrappers"
-
 	"sort"
-	"syscall"
 	"unsafe"
 )
 
+// The following structures define the format of message table resources, as in the Win32 header winnt.h.
+
+type messageResourceEntry struct {
+	Length uint16
+	Flags  uint16
+}
+
+type messageResourceBlock struct {
+	LowId           uint32
+	HighId          uint32
+	OffsetToEntries uint32
+}
+
+type messageResourceData struct {
+	NumberOfBlocks uint32
+}
+
 type idSlice []uint32
 
 func (ids idSlice) Len() int {
@@ -74,28 +88,26 @@ func EncodeMessageTable(messages map[uint32]string) []byte {
 		} else {
 			nullByteCount = 2
 		}
-		var entryHeader wrappers.MESSAGE_RESOURCE_ENTRY
+		var entryHeader messageResourceEntry
 		entryHeader.Length = uint16(unsafe.Sizeof(entryHeader)) + uint16(textByteLength) + nullByteCount
 		entryHeader.Flags = 1
 		entry := make([]byte, entryHeader.Length)
-		wrappers.RtlMoveMemory(
+		moveMemory(
 			&entry[0],
 			(*byte)(unsafe.Pointer(&entryHeader)),
 			unsafe.Sizeof(entryHeader))
-		wrappers.RtlMoveMemory(
-			&entry[unsafe.Sizeof(entryHeader)],
-			(*byte)(unsafe.Pointer(syscall.StringToUTF16Ptr(messageText))),
-			uintptr(textByteLength))
+		textStart := int(unsafe.Sizeof(entryHeader))
+		putUTF16String(entry[textStart:textStart+textByteLength], messageText)
 		entries = append(entries, entry)
 	}
 
 	// build the file header
-	header := wrappers.MESSAGE_RESOURCE_DATA{
+	header := messageResourceData{
 		NumberOfBlocks: uint32(len(blockLengths)),
 	}
 
 	// build the block headers
-	blocks := make([]wrappers.MESSAGE_RESOURCE_BLOCK, len(blockLengths))
+	blocks := make([]messageResourceBlock, len(blockLengths))
 	headerLength := uint32(unsafe.Sizeof(header) + unsafe.Sizeof(blocks[0])*uintptr(len(blocks)))
 	offset := headerLength
 	entryIndex := 0
@@ -113,10 +125,10 @@ func EncodeMessageTable(messages map[uint32]string) []byte {
 	// concatenate everything
 	data := make([]byte, headerLength)
 	offset = 0
-	wrappers.RtlMoveMemory(&data[offset], (*byte)(unsafe.Pointer(&header)), unsafe.Sizeof(header))
+	moveMemory(&data[offset], (*byte)(unsafe.Pointer(&header)), unsafe.Sizeof(header))
 	offset += uint32(unsafe.Sizeof(header))
 	for _, block := range blocks {
-		wrappers.RtlMoveMemory(&data[offset], (*byte)(unsafe.Pointer(&block)), unsafe.Sizeof(block))
+		moveMemory(&data[offset], (*byte)(unsafe.Pointer(&block)), unsafe.Sizeof(block))
 		offset += uint32(unsafe.Sizeof(block))
 	}
 	for _, entry := range entries {
@@ -0,0 +1,277 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Override sets the value of one field of a resource file, as given on the command line.  The path names the field
+// with its keys and list indexes separated by dots, such as version.stringFileInfo.specialBuild or
+// messageTable.0.messageText.
+type Override struct {
+	Path  string
+	Value string
+}
+
+// ParseOverride parses an override of the form path=value.
+func ParseOverride(text string) (Override, error) {
+	i := strings.IndexByte(text, '=')
+	if i <= 0 {
+		return Override{}, errors.New("override must have the form path=value")
+	}
+	return Override{Path: text[:i], Value: text[i+1:]}, nil
+}
+
+// Pointer returns the JSON pointer of the field named by the override.
+func (override Override) Pointer() string {
+	var pointer strings.Builder
+	for _, segment := range strings.Split(override.Path, ".") {
+		pointer.WriteString("/")
+		pointer.WriteString(escapeJSONPointer(segment))
+	}
+	return pointer.String()
+}
+
+// expandSchema returns the alternatives that a schema allows, following references and oneOf and anyOf lists.
+func expandSchema(root schema, s schema) []schema {
+	if ref, ok := s["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		return expandSchema(root, root["definitions"].(map[string]schema)[name])
+	}
+	var alternatives []schema
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if choices, ok := s[key].([]schema); ok {
+			for _, choice := range choices {
+				alternatives = append(alternatives, expandSchema(root, choice)...)
+			}
+		}
+	}
+	if len(alternatives) == 0 {
+		alternatives = []schema{s}
+	}
+	return alternatives
+}
+
+// childSchemas returns the schemas that apply to the named field or list element of values described by s.
+func childSchemas(root schema, s schema, segment string) []schema {
+	var children []schema
+	for _, alternative := range expandSchema(root, s) {
+		switch alternative["type"] {
+		case "object":
+			if properties, ok := alternative["properties"].(map[string]schema); ok {
+				if property, ok := properties[segment]; ok {
+					children = append(children, property)
+					continue
+				}
+			}
+			if additional, ok := alternative["additionalProperties"].(schema); ok {
+				children = append(children, additional)
+			}
+		case "array":
+			if _, err := strconv.Atoi(segment); err == nil {
+				children = append(children, alternative["items"].(schema))
+			}
+		}
+	}
+	return children
+}
+
+func matchesEnum(s schema, value string) bool {
+	names, ok := s["enum"].([]string)
+	if !ok {
+		return true
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// convertOverrideValue converts the text of an override to a value that one of the schemas allows, trying integers
+// and booleans before lists and strings.  A list is given as comma-separated strings.  The text is an error only if no
+// alternative allows it, in which case the reason that each one rejected it is given.
+func convertOverrideValue(root schema, schemas []schema, text string) (interface{}, error) {
+	var alternatives []schema
+	for _, s := range schemas {
+		alternatives = append(alternatives, expandSchema(root, s)...)
+	}
+	var failures []string
+	for _, alternative := range alternatives {
+		if alternative["type"] != "integer" {
+			continue
+		}
+		value, err := strconv.ParseInt(text, 0, 64)
+		if err != nil {
+			continue
+		}
+		if value < alternative["minimum"].(int64) || value > alternative["maximum"].(int64) {
+			failures = append(failures, fmt.Sprintf("value %s must be between %d and %d", text,
+				alternative["minimum"], alternative["maximum"]))
+			continue
+		}
+		return value, nil
+	}
+	for _, alternative := range alternatives {
+		if alternative["type"] == "boolean" && (text == "true" || text == "false") {
+			return text == "true", nil
+		}
+	}
+	for _, alternative := range alternatives {
+		if alternative["type"] != "array" {
+			continue
+		}
+		items := expandSchema(root, alternative["items"].(schema))
+		if len(items) != 1 || items[0]["type"] != "string" {
+			continue
+		}
+		var values []interface{}
+		for _, item := range strings.Split(text, ",") {
+			item = strings.TrimSpace(item)
+			if !matchesEnum(items[0], item) {
+				failures = append(failures, fmt.Sprintf("invalid value %s (expected a list of %s)", item,
+					strings.Join(items[0]["enum"].([]string), ", ")))
+				values = nil
+				break
+			}
+			values = append(values, item)
+		}
+		if values != nil {
+			return values, nil
+		}
+	}
+	var types []string
+	for _, alternative := range alternatives {
+		if alternative["type"] == "string" {
+			if matchesEnum(alternative, text) {
+				return text, nil
+			}
+			failures = append(failures, fmt.Sprintf("invalid value %s (expected one of %s)", text,
+				strings.Join(alternative["enum"].([]string), ", ")))
+			continue
+		}
+		// objects and lists of objects are set one field at a time
+		if typeName, ok := alternative["type"].(string); ok && typeName != "object" && typeName != "array" {
+			types = append(types, typeName)
+		}
+	}
+	if len(failures) > 0 {
+		return nil, errors.New(strings.Join(failures, "; "))
+	}
+	if len(types) == 0 {
+		return nil, errors.New("field cannot be set from the command line")
+	}
+	return nil, errors.New(fmt.Sprintf("invalid value %s (expected %s)", text, strings.Join(types, " or ")))
+}
+
+// applyOverride sets the field named by the override, creating any objects that contain it.
+func applyOverride(jsonData map[string]interface{}, override Override) (interface{}, error) {
+	root := schema(ResourceSchema())
+	segments := strings.Split(override.Path, ".")
+	if segments[0] == "extends" {
+		// base files are merged when the resource files are read, before any override is applied
+		return nil, errors.New("extends cannot be set from the command line; give the base files before the " +
+			"resource file instead")
+	}
+	schemas := []schema{root}
+	// isList records whether each field on the path holds a list, so that a missing list is not created as an object
+	isList := make([]bool, len(segments))
+	for i, segment := range segments {
+		var children []schema
+		for _, s := range schemas {
+			children = append(children, childSchemas(root, s, segment)...)
+		}
+		if len(children) == 0 {
+			return nil, errors.New(fmt.Sprintf("unknown field %s", segment))
+		}
+		for _, child := range children {
+			for _, alternative := range expandSchema(root, child) {
+				isList[i] = isList[i] || alternative["type"] == "array"
+			}
+		}
+		schemas = children
+	}
+	value, err := convertOverrideValue(root, schemas, override.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	var container interface{} = jsonData
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if last {
+				c[segment] = value
+			} else if child, ok := c[segment]; ok {
+				container = child
+			} else if isList[i] {
+				return nil, errors.New(fmt.Sprintf("no element %s in list %s", segments[i+1],
+					strings.Join(segments[:i+1], ".")))
+			} else {
+				child := make(map[string]interface{})
+				c[segment] = child
+				container = child
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(c) {
+				return nil, errors.New(fmt.Sprintf("no element %s in list %s", segment,
+					strings.Join(segments[:i], ".")))
+			}
+			if last {
+				c[index] = value
+			} else {
+				container = c[index]
+			}
+		default:
+			return nil, errors.New(fmt.Sprintf("%s is not an object or a list", strings.Join(segments[:i], ".")))
+		}
+	}
+	return value, nil
+}
+
+// ApplyOverrides sets the fields named by the overrides in the decoded resource data, converting each value to the
+// type that the schema gives for the field.  Positions in the source map for the overridden fields are replaced by
+// the position of the override, whose File names it as -set path.  The converted values are returned in order.
+func ApplyOverrides(
+	jsonData map[string]interface{},
+	sourceMap SourceMap,
+	overrides []Override) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(overrides))
+	var errs ErrorList
+	for _, override := range overrides {
+		value, err := applyOverride(jsonData, override)
+		if err != nil {
+			errs.add(errors.New(fmt.Sprintf("-set %s: %s", override.Path, err)))
+			continue
+		}
+		values = append(values, value)
+		if sourceMap != nil {
+			copySourceMap(sourceMap, override.Pointer(), SourceMap{"": Position{File: "-set " + override.Path}}, "")
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return values, nil
+}
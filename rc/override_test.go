@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	for _, test := range []struct {
+		Text  string
+		Value interface{}
+		Error string
+	}{
+		{"version.fileVersion=3.1.0.77", "3.1.0.77", ""},
+		{"version.stringFileInfo.specialBuild=hotfix", "hotfix", ""},
+		{"version.fileFlags=VS_FF_DEBUG, VS_FF_SPECIALBUILD", []interface{}{"VS_FF_DEBUG", "VS_FF_SPECIALBUILD"}, ""},
+		{"version.fileFlags=0x3", int64(3), ""},
+		{"version.fileFlags=VS_FF_DEBUG,VS_FF_BOGUS", nil, "invalid value VS_FF_BOGUS"},
+		{"version.fileType=VFT_DLL", "VFT_DLL", ""},
+		{"version.fileType=2", int64(2), ""},
+		{"version.fileType=0x100000000", nil, "must be between 0 and 4294967295"},
+		{"version.fileType=VFT_BOGUS", nil, "invalid value VFT_BOGUS (expected one of"},
+		{"manifest.uiAccess=true", true, ""},
+		{"manifest.uiAccess=yes", nil, "invalid value yes (expected boolean)"},
+		{"manifest.dpiAware=false", false, ""},
+		{"language=de-DE", "de-DE", ""},
+		// a class that is not predefined is accepted by the string alternative
+		{"dialogs.0.controls.0.class=MyControl", "MyControl", ""},
+		{"dialogs.0.controls.0.class=BUTTON", "BUTTON", ""},
+		{"dialogs.0.controls.0.class=0x80", int64(0x80), ""},
+		{"dialogs.0.controls.0.class=0", "0", ""},
+		{"dialogs.0.controls.1.class=BUTTON", nil, "no element 1 in list dialogs.0.controls"},
+		{"messageTable.0.messageText=hello", nil, "no element 0 in list messageTable"},
+		{"version.bogus=1", nil, "unknown field bogus"},
+		{"manifest=app.manifest", "app.manifest", ""},
+		{"extends=base.json", nil, "extends cannot be set from the command line"},
+	} {
+		override, err := ParseOverride(test.Text)
+		if err != nil {
+			t.Fatalf("%s: %s", test.Text, err)
+		}
+		jsonData := map[string]interface{}{
+			"dialogs": []interface{}{map[string]interface{}{"controls": []interface{}{map[string]interface{}{}}}},
+		}
+		sourceMap := make(SourceMap)
+		values, err := ApplyOverrides(jsonData, sourceMap, []Override{override})
+		if test.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), test.Error) {
+				t.Errorf("%s: got error %v, expected %s", test.Text, err, test.Error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.Text, err)
+			continue
+		}
+		if test.Value != nil && !reflect.DeepEqual(values[0], test.Value) {
+			t.Errorf("%s: got %#v, expected %#v", test.Text, values[0], test.Value)
+		}
+		if pos, ok := sourceMap.Lookup(override.Pointer()); !ok || pos.File != "-set "+override.Path {
+			t.Errorf("%s: the source map gives %v for the field", test.Text, pos)
+		}
+	}
+}
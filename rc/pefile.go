@@ -0,0 +1,270 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"unicode/utf16"
+)
+
+// IsDLL reports whether the PE file is a dynamic-link library rather than an executable program.
+func IsDLL(fileName string) (bool, error) {
+	f, err := pe.Open(fileName)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	return f.Characteristics&pe.IMAGE_FILE_DLL != 0, nil
+}
+
+const imageDirectoryEntryResource = 2
+
+// resourceDirectoryOffset returns the file offset of the resource directory of the PE file, or false if it has none.
+func resourceDirectoryOffset(f *pe.File) (uint32, bool) {
+	var dataDirectory pe.DataDirectory
+	switch header := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		if header.NumberOfRvaAndSizes > imageDirectoryEntryResource {
+			dataDirectory = header.DataDirectory[imageDirectoryEntryResource]
+		}
+	case *pe.OptionalHeader64:
+		if header.NumberOfRvaAndSizes > imageDirectoryEntryResource {
+			dataDirectory = header.DataDirectory[imageDirectoryEntryResource]
+		}
+	}
+	if dataDirectory.VirtualAddress == 0 {
+		return 0, false
+	}
+	return rvaToOffset(f, dataDirectory.VirtualAddress, 16)
+}
+
+// rvaToOffset returns the file offset of size bytes of data at a relative virtual address in the PE file.  The data
+// must lie within the virtual size of a section and within the part of the section that is stored in the file.
+func rvaToOffset(f *pe.File, rva uint32, size uint32) (uint32, bool) {
+	for _, section := range f.Sections {
+		virtualSize := section.VirtualSize
+		if virtualSize == 0 {
+			// some linkers leave the virtual size zero, in which case the loader uses the size of the raw data
+			virtualSize = section.Size
+		}
+		if rva < section.VirtualAddress || uint64(rva) >= uint64(section.VirtualAddress)+uint64(virtualSize) {
+			continue
+		}
+		end := uint64(rva-section.VirtualAddress) + uint64(size)
+		if end > uint64(virtualSize) || end > uint64(section.Size) {
+			return 0, false
+		}
+		return section.Offset + rva - section.VirtualAddress, true
+	}
+	return 0, false
+}
+
+// ExecutableResource is a resource stored in an executable, in one of the languages in which it may be present.
+type ExecutableResource struct {
+	Type     ResourceType // the predefined type, or zero if the type is given by TypeName
+	TypeName string
+	Id       uint
+	Name     string // if not empty, the resource is identified by name rather than by Id
+	Language Language
+	Data     []byte
+}
+
+// readResourceDirectory reads the entries of the resource directory at the given offset from the start of the
+// resource section, returning the name or ID of each entry and the offset of its subdirectory or data entry.
+func readResourceDirectory(data []byte, rsrcOffset uint32, offset uint32) ([]string, []uint32, []uint32, error) {
+	start := int(rsrcOffset) + int(offset)
+	if start+16 > len(data) {
+		return nil, nil, nil, errors.New("resource directory is truncated")
+	}
+	named := int(binary.LittleEndian.Uint16(data[start+12:]))
+	count := named + int(binary.LittleEndian.Uint16(data[start+14:]))
+	if start+16+8*count > len(data) {
+		return nil, nil, nil, errors.New("resource directory is truncated")
+	}
+	names := make([]string, count)
+	ids := make([]uint32, count)
+	offsets := make([]uint32, count)
+	for i := 0; i < count; i++ {
+		name := binary.LittleEndian.Uint32(data[start+16+8*i:])
+		offsets[i] = binary.LittleEndian.Uint32(data[start+16+8*i+4:])
+		if name&imageResourceNameIsString == 0 {
+			ids[i] = name
+			continue
+		}
+		nameStart := int(rsrcOffset) + int(name&^imageResourceNameIsString)
+		if nameStart+2 > len(data) {
+			return nil, nil, nil, errors.New("resource name is truncated")
+		}
+		length := int(binary.LittleEndian.Uint16(data[nameStart:]))
+		if nameStart+2+2*length > len(data) {
+			return nil, nil, nil, errors.New("resource name is truncated")
+		}
+		chars := make([]uint16, length)
+		for j := range chars {
+			chars[j] = binary.LittleEndian.Uint16(data[nameStart+2+2*j:])
+		}
+		names[i] = string(utf16.Decode(chars))
+	}
+	return names, ids, offsets, nil
+}
+
+// ReadExecutableResources returns the resources stored in a PE file, in the order of its resource directory.
+func ReadExecutableResources(fileName string) ([]*ExecutableResource, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	f, err := pe.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	rsrcOffset, ok := resourceDirectoryOffset(f)
+	if !ok {
+		return nil, nil
+	}
+	var resources []*ExecutableResource
+	typeNames, typeIds, typeOffsets, err := readResourceDirectory(data, rsrcOffset, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range typeOffsets {
+		if typeOffsets[i]&imageResourceDataIsDir == 0 {
+			return nil, errors.New("resource type entry is not a directory")
+		}
+		names, ids, nameOffsets, err := readResourceDirectory(data, rsrcOffset, typeOffsets[i]&^imageResourceDataIsDir)
+		if err != nil {
+			return nil, err
+		}
+		for j := range nameOffsets {
+			if nameOffsets[j]&imageResourceDataIsDir == 0 {
+				return nil, errors.New("resource name entry is not a directory")
+			}
+			_, languages, dataEntryOffsets, err := readResourceDirectory(data, rsrcOffset,
+				nameOffsets[j]&^imageResourceDataIsDir)
+			if err != nil {
+				return nil, err
+			}
+			for k := range dataEntryOffsets {
+				entryStart := int(rsrcOffset) + int(dataEntryOffsets[k]&^imageResourceDataIsDir)
+				if dataEntryOffsets[k]&imageResourceDataIsDir != 0 || entryStart+16 > len(data) {
+					return nil, errors.New("invalid resource data entry")
+				}
+				rva := binary.LittleEndian.Uint32(data[entryStart:])
+				size := binary.LittleEndian.Uint32(data[entryStart+4:])
+				dataOffset, ok := rvaToOffset(f, rva, size)
+				if !ok || uint64(dataOffset)+uint64(size) > uint64(len(data)) {
+					return nil, errors.New("resource data is outside the image")
+				}
+				res := &ExecutableResource{
+					TypeName: typeNames[i],
+					Id:       uint(ids[j]),
+					Name:     names[j],
+					Language: Language(languages[k]),
+					Data:     data[dataOffset : dataOffset+size],
+				}
+				if res.TypeName == "" {
+					res.Type = ResourceType(typeIds[i])
+				}
+				resources = append(resources, res)
+			}
+		}
+	}
+	return resources, nil
+}
+
+// setDirectoryTimestamps sets the TimeDateStamp field of the resource directory at the given offset from the start of
+// the resource section, and of its subdirectories.
+func setDirectoryTimestamps(data []byte, rsrcOffset uint32, offset uint32, depth int, timestamp uint32) error {
+	// the directory tree has three levels: type, name and language
+	if depth > 3 {
+		return errors.New("resource directory is nested too deeply")
+	}
+	start := int(rsrcOffset) + int(offset)
+	if start+16 > len(data) {
+		return errors.New("resource directory is truncated")
+	}
+	binary.LittleEndian.PutUint32(data[start+4:], timestamp)
+	count := int(binary.LittleEndian.Uint16(data[start+12:])) + int(binary.LittleEndian.Uint16(data[start+14:]))
+	if start+16+8*count > len(data) {
+		return errors.New("resource directory is truncated")
+	}
+	for i := 0; i < count; i++ {
+		entryOffset := binary.LittleEndian.Uint32(data[start+16+8*i+4:])
+		if entryOffset&0x80000000 != 0 {
+			if err := setDirectoryTimestamps(data, rsrcOffset, entryOffset&^0x80000000, depth+1, timestamp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// peChecksum computes the checksum of a PE file in the same way as CheckSumMappedFile, treating the checksum field at
+// the given offset as zero.
+func peChecksum(data []byte, checksumOffset int) uint32 {
+	var sum uint64
+	for i := 0; i < len(data); i += 2 {
+		if i == checksumOffset || i == checksumOffset+2 {
+			continue
+		}
+		var word uint64
+		if i+1 < len(data) {
+			word = uint64(binary.LittleEndian.Uint16(data[i:]))
+		} else {
+			word = uint64(data[i])
+		}
+		sum += word
+		sum = (sum & 0xFFFF) + (sum >> 16)
+	}
+	sum = (sum & 0xFFFF) + (sum >> 16)
+	return uint32(sum) + uint32(len(data))
+}
+
+// SetResourceTimestamps replaces the timestamps that the resource update left in the resource directory of the PE
+// file, so that the same resources always produce the same file.  If the file has a checksum, it is updated to match.
+func SetResourceTimestamps(fileName string, timestamp uint32) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	f, err := pe.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	rsrcOffset, ok := resourceDirectoryOffset(f)
+	if !ok {
+		return nil
+	}
+	if err := setDirectoryTimestamps(data, rsrcOffset, 0, 1, timestamp); err != nil {
+		return err
+	}
+	// the checksum is at the same offset in the 32-bit and 64-bit optional headers
+	checksumOffset := int(binary.LittleEndian.Uint32(data[0x3C:])) + 4 + binary.Size(f.FileHeader) + 64
+	if checksumOffset+4 <= len(data) && binary.LittleEndian.Uint32(data[checksumOffset:]) != 0 {
+		binary.LittleEndian.PutUint32(data[checksumOffset:], peChecksum(data, checksumOffset))
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, data, info.Mode())
+}
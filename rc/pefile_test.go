@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package rc
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	testSectionAddress = 0x1000
+	testFileAlignment  = 0x200
+	testChecksumOffset = 0x40 + 4 + 20 + 64
+)
+
+// buildTestExecutable lays out a minimal 64-bit PE image whose only section holds the resources, with the given
+// timestamp in every resource directory and the given checksum, as the resource update functions may leave them.
+func buildTestExecutable(t *testing.T, resources []*Resource, timestamp uint32, checksum uint32) []byte {
+	section, relocations, err := encodeResourceSection(resources, 0x0409)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, offset := range relocations {
+		binary.LittleEndian.PutUint32(section[offset:], binary.LittleEndian.Uint32(section[offset:])+testSectionAddress)
+	}
+	if err := setDirectoryTimestamps(section, 0, 0, 1, timestamp); err != nil {
+		t.Fatal(err)
+	}
+	rawSize := (uint32(len(section)) + testFileAlignment - 1) &^ (testFileAlignment - 1)
+
+	var buf bytes.Buffer
+	dosHeader := make([]byte, 0x40)
+	copy(dosHeader, "MZ")
+	binary.LittleEndian.PutUint32(dosHeader[0x3C:], 0x40)
+	buf.Write(dosHeader)
+	buf.WriteString("PE\x00\x00")
+	binary.Write(&buf, binary.LittleEndian, pe.FileHeader{
+		Machine:              pe.IMAGE_FILE_MACHINE_AMD64,
+		NumberOfSections:     1,
+		SizeOfOptionalHeader: uint16(binary.Size(pe.OptionalHeader64{})),
+		Characteristics:      pe.IMAGE_FILE_EXECUTABLE_IMAGE | pe.IMAGE_FILE_LARGE_ADDRESS_AWARE,
+	})
+	optionalHeader := pe.OptionalHeader64{
+		Magic:               0x20B,
+		ImageBase:           0x140000000,
+		SectionAlignment:    0x1000,
+		FileAlignment:       testFileAlignment,
+		SizeOfImage:         testSectionAddress + (rawSize+0xFFF)&^0xFFF,
+		SizeOfHeaders:       testFileAlignment,
+		CheckSum:            checksum,
+		Subsystem:           pe.IMAGE_SUBSYSTEM_WINDOWS_CUI,
+		NumberOfRvaAndSizes: 16,
+	}
+	optionalHeader.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_RESOURCE] = pe.DataDirectory{
+		VirtualAddress: testSectionAddress,
+		Size:           uint32(len(section)),
+	}
+	binary.Write(&buf, binary.LittleEndian, optionalHeader)
+	sectionHeader := pe.SectionHeader32{
+		VirtualSize:      uint32(len(section)),
+		VirtualAddress:   testSectionAddress,
+		SizeOfRawData:    rawSize,
+		PointerToRawData: testFileAlignment,
+		Characteristics:  pe.IMAGE_SCN_CNT_INITIALIZED_DATA | pe.IMAGE_SCN_MEM_READ,
+	}
+	copy(sectionHeader.Name[:], ".rsrc")
+	binary.Write(&buf, binary.LittleEndian, sectionHeader)
+	buf.Write(make([]byte, testFileAlignment-buf.Len()))
+	buf.Write(section)
+	buf.Write(make([]byte, int(rawSize)-len(section)))
+	return buf.Bytes()
+}
+
+func testResources() []*Resource {
+	return []*Resource{
+		{Type: ResourceTypeVersion, Id: 1, Data: []byte("version data")},
+		{Type: ResourceTypeManifest, Id: 1, Data: []byte("<assembly/>")},
+		{Type: ResourceTypeRCData, Name: "CONFIG", Data: []byte("config data")},
+	}
+}
+
+// stampTestExecutable writes the executable to a file, sets its resource timestamps and returns the result.
+func stampTestExecutable(t *testing.T, data []byte, timestamp uint32) []byte {
+	fileName := filepath.Join(t.TempDir(), "hello.exe")
+	if err := ioutil.WriteFile(fileName, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetResourceTimestamps(fileName, timestamp); err != nil {
+		t.Fatalf("failed to set resource timestamps: %s", err)
+	}
+	result, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestSetResourceTimestampsIsReproducible(t *testing.T) {
+	first := stampTestExecutable(t, buildTestExecutable(t, testResources(), 0x5F000000, 1), 0)
+	second := stampTestExecutable(t, buildTestExecutable(t, testResources(), 0x60000000, 1), 0)
+	if !bytes.Equal(first, second) {
+		t.Fatal("executables updated at different times differ after their resource timestamps are set")
+	}
+}
+
+func TestSetResourceTimestampsChecksum(t *testing.T) {
+	data := stampTestExecutable(t, buildTestExecutable(t, testResources(), 0x5F000000, 1), 1234)
+	checksum := binary.LittleEndian.Uint32(data[testChecksumOffset:])
+	if expected := peChecksum(data, testChecksumOffset); checksum != expected {
+		t.Fatalf("checksum is 0x%08X, expected 0x%08X", checksum, expected)
+	}
+	// the checksum of the image with the stale timestamps must differ, or the test would not show it being updated
+	if checksum == peChecksum(buildTestExecutable(t, testResources(), 0x5F000000, 1), testChecksumOffset) {
+		t.Fatal("checksum was not updated")
+	}
+
+	// files without a checksum are left without one
+	data = stampTestExecutable(t, buildTestExecutable(t, testResources(), 0x5F000000, 0), 1234)
+	if checksum := binary.LittleEndian.Uint32(data[testChecksumOffset:]); checksum != 0 {
+		t.Fatalf("checksum is 0x%08X, expected none", checksum)
+	}
+}
+
+func TestSetResourceTimestampsValue(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "hello.exe")
+	if err := ioutil.WriteFile(fileName, buildTestExecutable(t, testResources(), 0x5F000000, 1), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetResourceTimestamps(fileName, 1234); err != nil {
+		t.Fatalf("failed to set resource timestamps: %s", err)
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := buildTestExecutable(t, testResources(), 1234, 1)
+	if !bytes.Equal(data[testFileAlignment:], expected[testFileAlignment:]) {
+		t.Fatal("resource section differs from one built with the timestamp")
+	}
+
+	// the resources must still be readable
+	resources, err := ReadExecutableResources(fileName)
+	if err != nil {
+		t.Fatalf("failed to read resources: %s", err)
+	}
+	expectedResources := testResources()
+	SortResources(expectedResources)
+	if len(resources) != len(expectedResources) {
+		t.Fatalf("read %d resources, expected %d", len(resources), len(expectedResources))
+	}
+	for i, res := range expectedResources {
+		found := resources[i]
+		if found.Type != res.Type || found.Id != res.Id || found.Name != res.Name || !bytes.Equal(found.Data, res.Data) {
+			t.Fatalf("resource %d is %s, expected %s", i, found, (&ResourceSet{}).executableResource(res))
+		}
+	}
+}
+
+func TestRVAToOffset(t *testing.T) {
+	section := func(virtualAddress, virtualSize, size, offset uint32) *pe.Section {
+		return &pe.Section{SectionHeader: pe.SectionHeader{
+			VirtualAddress: virtualAddress,
+			VirtualSize:    virtualSize,
+			Size:           size,
+			Offset:         offset,
+		}}
+	}
+	f := &pe.File{Sections: []*pe.Section{
+		// more of the section is stored in the file than is mapped, as the raw size is rounded up
+		section(0x1000, 0x180, 0x200, 0x400),
+		// less of the section is stored in the file than is mapped, the rest being zero
+		section(0x2000, 0x800, 0x200, 0x600),
+		// a virtual size of zero means that the raw size is mapped
+		section(0x3000, 0, 0x200, 0x800),
+		section(0xFFFFFF00, 0x100, 0x100, 0xA00),
+	}}
+	for _, test := range []struct {
+		RVA    uint32
+		Size   uint32
+		Offset uint32
+		OK     bool
+	}{
+		{0x1000, 16, 0x400, true},
+		{0x1170, 16, 0x570, true},
+		{0x1178, 16, 0, false},
+		{0x1180, 0, 0, false},
+		{0x2100, 0x100, 0x700, true},
+		{0x2100, 0x101, 0, false},
+		{0x2300, 16, 0, false},
+		{0x3100, 0x100, 0x900, true},
+		{0x3100, 0x101, 0, false},
+		{0xFFFFFFF0, 16, 0xAF0, true},
+		{0xFFFFFFF0, 17, 0, false},
+		{0x0800, 16, 0, false},
+	} {
+		offset, ok := rvaToOffset(f, test.RVA, test.Size)
+		if ok != test.OK || offset != test.Offset {
+			t.Errorf("RVA 0x%X, size 0x%X: got 0x%X, %t, expected 0x%X, %t", test.RVA, test.Size, offset, ok,
+				test.Offset, test.OK)
+		}
+	}
+}
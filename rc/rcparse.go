@@ -0,0 +1,1858 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+type Resource struct {
+	Type ResourceType
+	Id	 uint
+	Name string // if not empty, the resource is identified by name rather than by Id
+	Data []byte
+}
+
+// SortResources sorts resources into the order of a resource directory: by type, then with named resources before
+// numbered ones, names being compared case-insensitively.  This keeps the output the same from run to run.
+func SortResources(resources []*Resource) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if (a.Name != "") != (b.Name != "") {
+			return a.Name != ""
+		}
+		if a.Name != "" {
+			return strings.ToUpper(a.Name) < strings.ToUpper(b.Name)
+		}
+		return a.Id < b.Id
+	})
+}
+
+// PathError records the JSON pointer of the value that caused an error, so that the error can be reported at the
+// position of that value in the source file.
+type PathError struct {
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+// ErrorList holds every error found while parsing resources, so that they can all be reported in one run.
+type ErrorList []error
+
+func (list ErrorList) Error() string {
+	messages := make([]string, 0, len(list))
+	for _, err := range list {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// add appends an error to the list, flattening it if it is itself a list.
+func (list *ErrorList) add(err error) {
+	if nested, ok := err.(ErrorList); ok {
+		*list = append(*list, nested...)
+	} else {
+		*list = append(*list, err)
+	}
+}
+
+// withPath prefixes the JSON pointer of an error, or of every error in a list, with the path of the enclosing value.
+func withPath(err error, path string) error {
+	switch e := err.(type) {
+	case ErrorList:
+		result := make(ErrorList, 0, len(e))
+		for _, nested := range e {
+			result = append(result, withPath(nested, path))
+		}
+		return result
+	case *PathError:
+		return &PathError{Path: path + e.Path, Err: e.Err}
+	default:
+		return &PathError{Path: path, Err: err}
+	}
+}
+
+// atField tags an error with the JSON pointer of a field of the object being parsed.
+func atField(fieldName string, err error) error {
+	return withPath(err, "/"+escapeJSONPointer(fieldName))
+}
+
+// fieldError returns an error about a field of the object being parsed.  The message is prefixed with the field name.
+func fieldError(fieldName string, format string, args ...interface{}) error {
+	return atField(fieldName, errors.New(fmt.Sprintf("field %s %s", fieldName, fmt.Sprintf(format, args...))))
+}
+
+type stringFileInfoField struct {
+	JsonName string
+	WinName  string
+}
+
+var stringFileInfoFields = []stringFileInfoField{
+	{JsonName: "comments",         WinName: "Comments"},
+	{JsonName: "companyName",      WinName: "CompanyName"},
+	{JsonName: "fileDescription",  WinName: "FileDescription"},
+	{JsonName: "fileVersion",      WinName: "FileVersion"},
+	{JsonName: "internalName",     WinName: "InternalName"},
+	{JsonName: "legalCopyright",   WinName: "LegalCopyright"},
+	{JsonName: "legalTrademarks",  WinName: "LegalTrademarks"},
+	{JsonName: "originalFilename", WinName: "OriginalFilename"},
+	{JsonName: "privateBuild",     WinName: "PrivateBuild"},
+	{JsonName: "productName",      WinName: "ProductName"},
+	{JsonName: "productVersion",   WinName: "ProductVersion"},
+	{JsonName: "specialBuild",     WinName: "SpecialBuild"},
+}
+
+// namedValue associates a name accepted in the resource file with the value of the constant that it stands for.
+type namedValue struct {
+	Name  string
+	Value uint32
+}
+
+func lookupNamedValue(names []namedValue, name string) (uint32, bool) {
+	for _, named := range names {
+		if named.Name == name {
+			return named.Value, true
+		}
+	}
+	return 0, false
+}
+
+func lookupValueName(names []namedValue, value uint32) string {
+	for _, named := range names {
+		if named.Value == value {
+			return named.Name
+		}
+	}
+	return fmt.Sprintf("%d", value)
+}
+
+var fileFlagNames = []namedValue{
+	{Name: "VS_FF_DEBUG",        Value: VS_FF_DEBUG},
+	{Name: "VS_FF_PRERELEASE",   Value: VS_FF_PRERELEASE},
+	{Name: "VS_FF_PATCHED",      Value: VS_FF_PATCHED},
+	{Name: "VS_FF_PRIVATEBUILD", Value: VS_FF_PRIVATEBUILD},
+	{Name: "VS_FF_INFOINFERRED", Value: VS_FF_INFOINFERRED},
+	{Name: "VS_FF_SPECIALBUILD", Value: VS_FF_SPECIALBUILD},
+}
+
+var fileOSNames = []namedValue{
+	{Name: "VOS_UNKNOWN",       Value: VOS_UNKNOWN},
+	{Name: "VOS_DOS",           Value: VOS_DOS},
+	{Name: "VOS_OS216",         Value: VOS_OS216},
+	{Name: "VOS_OS232",         Value: VOS_OS232},
+	{Name: "VOS_NT",            Value: VOS_NT},
+	{Name: "VOS__WINDOWS16",    Value: VOS__WINDOWS16},
+	{Name: "VOS__PM16",         Value: VOS__PM16},
+	{Name: "VOS__PM32",         Value: VOS__PM32},
+	{Name: "VOS__WINDOWS32",    Value: VOS__WINDOWS32},
+	{Name: "VOS_DOS_WINDOWS16", Value: VOS_DOS_WINDOWS16},
+	{Name: "VOS_DOS_WINDOWS32", Value: VOS_DOS_WINDOWS32},
+	{Name: "VOS_OS216_PM16",    Value: VOS_OS216_PM16},
+	{Name: "VOS_OS232_PM32",    Value: VOS_OS232_PM32},
+	{Name: "VOS_NT_WINDOWS32",  Value: VOS_NT_WINDOWS32},
+}
+
+var fileTypeNames = []namedValue{
+	{Name: "VFT_UNKNOWN",    Value: VFT_UNKNOWN},
+	{Name: "VFT_APP",        Value: VFT_APP},
+	{Name: "VFT_DLL",        Value: VFT_DLL},
+	{Name: "VFT_DRV",        Value: VFT_DRV},
+	{Name: "VFT_FONT",       Value: VFT_FONT},
+	{Name: "VFT_VXD",        Value: VFT_VXD},
+	{Name: "VFT_STATIC_LIB", Value: VFT_STATIC_LIB},
+}
+
+// File subtypes by name, with the file type that each requires.  VFT2_UNKNOWN may be used with any file type.
+var fileSubtypeNames = []struct {
+	namedValue
+	FileType uint32
+}{
+	{namedValue{Name: "VFT2_UNKNOWN",               Value: VFT2_UNKNOWN},               VFT_UNKNOWN},
+	{namedValue{Name: "VFT2_DRV_PRINTER",           Value: VFT2_DRV_PRINTER},           VFT_DRV},
+	{namedValue{Name: "VFT2_DRV_KEYBOARD",          Value: VFT2_DRV_KEYBOARD},          VFT_DRV},
+	{namedValue{Name: "VFT2_DRV_LANGUAGE",          Value: VFT2_DRV_LANGUAGE},          VFT_DRV},
+	{namedValue{Name: "VFT2_DRV_DISPLAY",           Value: VFT2_DRV_DISPLAY},           VFT_DRV},
+	{namedValue{Name: "VFT2_DRV_MOUSE",             Value: VFT2_DRV_MOUSE},             VFT_DRV},
+	{namedValue{Name: "VFT2_DRV_NETWORK",           Value: VFT2_DRV_NETWORK},           VFT_DRV},
+	{namedValue{Name: "VFT2_DRV_SYSTEM",            Value: VFT2_DRV_SYSTEM},            VFT_DRV},
+	{namedValue{Name: "VFT2_DRV_INSTALLABLE",       Value: VFT2_DRV_INSTALLABLE},       VFT_DRV},
+	{namedValue{Name: "VFT2_DRV_SOUND",             Value: VFT2_DRV_SOUND},             VFT_DRV},
+	{namedValue{Name: "VFT2_DRV_COMM",              Value: VFT2_DRV_COMM},              VFT_DRV},
+	{namedValue{Name: "VFT2_DRV_VERSIONED_PRINTER", Value: VFT2_DRV_VERSIONED_PRINTER}, VFT_DRV},
+	{namedValue{Name: "VFT2_FONT_RASTER",           Value: VFT2_FONT_RASTER},           VFT_FONT},
+	{namedValue{Name: "VFT2_FONT_VECTOR",           Value: VFT2_FONT_VECTOR},           VFT_FONT},
+	{namedValue{Name: "VFT2_FONT_TRUETYPE",         Value: VFT2_FONT_TRUETYPE},         VFT_FONT},
+}
+
+var messageSeverityNames = []namedValue{
+	{Name: "Success",       Value: 0x00000000},
+	{Name: "Informational", Value: 0x40000000},
+	{Name: "Warning",       Value: 0x80000000},
+	{Name: "Error",         Value: 0xC0000000},
+}
+
+func parseFileFlags(fileFlagsObj interface{}, fieldName string) (uint32, error) {
+	if _, ok := fileFlagsObj.(int64); ok {
+		fileFlags, err := parseInteger(fileFlagsObj, fieldName, 0, math.MaxUint32)
+		return uint32(fileFlags), err
+	}
+	fileFlagsArray, ok := fileFlagsObj.([]interface{})
+	if !ok {
+		return 0, fieldError(fieldName, "must specify a list of strings or an integer")
+	}
+	var fileFlags uint32
+	var errs ErrorList
+	for i, flagNameObj := range fileFlagsArray {
+		flagName, ok := flagNameObj.(string)
+		if !ok {
+			return 0, fieldError(fieldName, "must specify a list of strings or an integer")
+		}
+		if value, ok := lookupNamedValue(fileFlagNames, flagName); ok {
+			fileFlags |= value
+		} else {
+			errs.add(withPath(errors.New(fmt.Sprintf("invalid file flag: %s", flagName)), fmt.Sprintf("/%d", i)))
+		}
+	}
+	if len(errs) > 0 {
+		return 0, atField(fieldName, errs)
+	}
+	return fileFlags, nil
+}
+
+func parseFileOS(fileOSObj interface{}) (uint32, error) {
+	if _, ok := fileOSObj.(int64); ok {
+		fileOS, err := parseInteger(fileOSObj, "fileOS", 0, math.MaxUint32)
+		return uint32(fileOS), err
+	}
+	fileOSName, ok := fileOSObj.(string)
+	if !ok {
+		return 0, fieldError("fileOS", "must specify a string or an integer")
+	}
+	fileOS, ok := lookupNamedValue(fileOSNames, fileOSName)
+	if !ok {
+		return 0, atField("fileOS", errors.New(fmt.Sprintf("invalid file OS: %s", fileOSName)))
+	}
+	return fileOS, nil
+}
+
+func parseFileType(fileTypeObj interface{}) (uint32, error) {
+	if _, ok := fileTypeObj.(int64); ok {
+		fileType, err := parseInteger(fileTypeObj, "fileType", 0, math.MaxUint32)
+		return uint32(fileType), err
+	}
+	fileTypeName, ok := fileTypeObj.(string)
+	if !ok {
+		return 0, fieldError("fileType", "must specify a string or an integer")
+	}
+	fileType, ok := lookupNamedValue(fileTypeNames, fileTypeName)
+	if !ok {
+		return 0, atField("fileType", errors.New(fmt.Sprintf("invalid file type: %s", fileTypeName)))
+	}
+	return fileType, nil
+}
+
+// parseFileSubtype parses the fileSubtype field, whose meaning depends on the file type: driver types for VFT_DRV,
+// font types for VFT_FONT and a virtual device identifier for VFT_VXD.  Other file types have no subtype.
+func parseFileSubtype(fileSubtypeObj interface{}, fileType uint32) (uint32, error) {
+	if _, ok := fileSubtypeObj.(int64); ok {
+		fileSubtype, err := parseInteger(fileSubtypeObj, "fileSubtype", 0, math.MaxUint32)
+		if err != nil {
+			return 0, err
+		}
+		switch fileType {
+		case VFT_DRV, VFT_FONT, VFT_VXD:
+		default:
+			if fileSubtype != VFT2_UNKNOWN {
+				return 0, fieldError("fileSubtype", "must be zero unless fileType is VFT_DRV, VFT_FONT or VFT_VXD")
+			}
+		}
+		return uint32(fileSubtype), nil
+	}
+	fileSubtypeName, ok := fileSubtypeObj.(string)
+	if !ok {
+		return 0, fieldError("fileSubtype", "must specify a string or an integer")
+	}
+	for _, subtype := range fileSubtypeNames {
+		if subtype.Name != fileSubtypeName {
+			continue
+		}
+		if subtype.Value != VFT2_UNKNOWN && fileType != subtype.FileType {
+			return 0, fieldError("fileSubtype", "%s requires file type %s", fileSubtypeName,
+				lookupValueName(fileTypeNames, subtype.FileType))
+		}
+		return subtype.Value, nil
+	}
+	return 0, atField("fileSubtype", errors.New(fmt.Sprintf("invalid file subtype: %s", fileSubtypeName)))
+}
+
+// parseFileDate converts an RFC 3339 timestamp into the two halves of a FILETIME.
+func parseFileDate(fileDateObj interface{}) (uint32, uint32, error) {
+	fileDateStr, ok := fileDateObj.(string)
+	if !ok {
+		return 0, 0, fieldError("fileDate", "must specify a string")
+	}
+	fileDate, err := time.Parse(time.RFC3339, fileDateStr)
+	if err != nil {
+		return 0, 0, atField("fileDate", errors.New(fmt.Sprintf("invalid file date: %s", fileDateStr)))
+	}
+	// FILETIME counts 100-nanosecond intervals since January 1, 1601, which is 11644473600 seconds before the Unix
+	// epoch.  Working from seconds keeps dates outside the range of UnixNano exact.
+	const filetimeEpochOffset = 11644473600
+	seconds := fileDate.Unix() + filetimeEpochOffset
+	if seconds < 0 {
+		return 0, 0, atField("fileDate", errors.New(fmt.Sprintf("file date is before 1601: %s", fileDateStr)))
+	}
+	filetime := uint64(seconds)*10000000 + uint64(fileDate.Nanosecond()/100)
+	return uint32(filetime >> 32), uint32(filetime), nil
+}
+
+// parseVersionNumber converts a dotted version number or a semantic version into the two halves of a file version
+// number.  The semantic version is returned if the string was parsed as one.
+func parseVersionNumber(versionStr string, semverRule string) (uint32, uint32, *SemanticVersion, error) {
+	if parts, err := parseFileVersionNumber(versionStr); err == nil {
+		return makeLong(parts[1], parts[0]), makeLong(parts[3], parts[2]), nil, nil
+	}
+	semver, err := ParseSemanticVersion(versionStr)
+	if err != nil {
+		return 0, 0, nil, errors.New(fmt.Sprintf("invalid version number: %s", versionStr))
+	}
+	parts, err := semver.FileVersion(semverRule)
+	if err != nil {
+		return 0, 0, nil, errors.New(fmt.Sprintf("invalid version number: %s (%s)", versionStr, err))
+	}
+	return makeLong(parts[1], parts[0]), makeLong(parts[3], parts[2]), semver, nil
+}
+
+// versionFieldNames are the fields that may be given in the version object.
+var versionFieldNames = []string{
+	"strucVersion", "semverRule", "fileVersion", "productVersion", "fileFlagsMask", "fileFlags", "fileOS", "fileType",
+	"fileSubtype", "fileDate", "fileDateMS", "fileDateLS", "buildInfo", "stringFileInfo",
+}
+
+func parseVersionResource(
+	versionJson map[string]interface{},
+	language Language,
+	targetFileName string) (*Resource, error) {
+	var errs ErrorList
+	for key := range versionJson {
+		valid := false
+		for _, fieldName := range versionFieldNames {
+			valid = valid || key == fieldName
+		}
+		if !valid {
+			errs.add(atField(key, errors.New(fmt.Sprintf("invalid version field %s", key))))
+		}
+	}
+	versionJson, err := applyGoBuildInfo(versionJson, targetFileName)
+	if err != nil {
+		errs.add(err)
+		return nil, errs
+	}
+	fixedFileInfo := VS_FIXEDFILEINFO{
+		Signature:     VS_FFI_SIGNATURE,
+		FileFlagsMask: VS_FFI_FILEFLAGSMASK,
+	}
+	if strucVersionObj, ok := versionJson["strucVersion"]; ok {
+		if strucVersion, err := parseInteger(strucVersionObj, "strucVersion", 0, math.MaxUint32); err != nil {
+			errs.add(err)
+		} else {
+			fixedFileInfo.StrucVersion = uint32(strucVersion)
+		}
+	}
+	semverRule := DefaultSemverRule
+	if semverRuleObj, ok := versionJson["semverRule"]; ok {
+		if semverRule, ok = semverRuleObj.(string); !ok {
+			errs.add(fieldError("semverRule", "must specify a string"))
+			semverRule = DefaultSemverRule
+		} else if err := ValidateSemverRule(semverRule); err != nil {
+			errs.add(atField("semverRule", err))
+			semverRule = DefaultSemverRule
+		}
+	}
+	// the version whose prerelease identifiers set VS_FF_PRERELEASE, if any
+	var prereleaseVersion string
+	defaultStrings := make(map[string]string)
+	if fileVersionObj, ok := versionJson["fileVersion"]; ok {
+		if fileVersionStr, ok := fileVersionObj.(string); ok {
+			if ms, ls, semver, err := parseVersionNumber(fileVersionStr, semverRule); err != nil {
+				errs.add(atField("fileVersion", err))
+			} else {
+				fixedFileInfo.FileVersionMS = ms
+				fixedFileInfo.FileVersionLS = ls
+				if semver != nil && len(semver.Prerelease) > 0 {
+					prereleaseVersion = fileVersionStr
+				}
+			}
+		} else {
+			errs.add(fieldError("fileVersion", "must specify a string"))
+		}
+	}
+	if productVersionObj, ok := versionJson["productVersion"]; ok {
+		if productVersionStr, ok := productVersionObj.(string); ok {
+			if ms, ls, semver, err := parseVersionNumber(productVersionStr, semverRule); err != nil {
+				errs.add(atField("productVersion", err))
+			} else {
+				fixedFileInfo.ProductVersionMS = ms
+				fixedFileInfo.ProductVersionLS = ls
+				if semver != nil {
+					if len(semver.Prerelease) > 0 && prereleaseVersion == "" {
+						prereleaseVersion = productVersionStr
+					}
+					defaultStrings["productVersion"] = productVersionStr
+				}
+			}
+		} else {
+			errs.add(fieldError("productVersion", "must specify a string"))
+		}
+	}
+	flagsValid := true
+	if fileFlagsMaskObj, ok := versionJson["fileFlagsMask"]; ok {
+		if fileFlagsMask, err := parseFileFlags(fileFlagsMaskObj, "fileFlagsMask"); err != nil {
+			errs.add(err)
+			flagsValid = false
+		} else {
+			fixedFileInfo.FileFlagsMask = fileFlagsMask
+		}
+	}
+	if fileFlagsObj, ok := versionJson["fileFlags"]; ok {
+		if fileFlags, err := parseFileFlags(fileFlagsObj, "fileFlags"); err != nil {
+			errs.add(err)
+			flagsValid = false
+		} else {
+			fixedFileInfo.FileFlags = fileFlags
+		}
+	}
+	if prereleaseVersion != "" {
+		if flagsValid && fixedFileInfo.FileFlagsMask&VS_FF_PRERELEASE == 0 {
+			errs.add(atField("fileFlagsMask", errors.New(fmt.Sprintf(
+				"fileFlagsMask must include VS_FF_PRERELEASE, which the prerelease version %s sets", prereleaseVersion))))
+			flagsValid = false
+		}
+		fixedFileInfo.FileFlags |= VS_FF_PRERELEASE
+	}
+	if flagsValid && fixedFileInfo.FileFlags&^fixedFileInfo.FileFlagsMask != 0 {
+		errs.add(fieldError("fileFlags", "contains flags that are not in fileFlagsMask"))
+	}
+	if fileOSObj, ok := versionJson["fileOS"]; ok {
+		if fileOS, err := parseFileOS(fileOSObj); err != nil {
+			errs.add(err)
+		} else {
+			fixedFileInfo.FileOS = fileOS
+		}
+	}
+	fileTypeValid := true
+	if fileTypeObj, ok := versionJson["fileType"]; ok {
+		if fileType, err := parseFileType(fileTypeObj); err != nil {
+			errs.add(err)
+			fileTypeValid = false
+		} else {
+			fixedFileInfo.FileType = fileType
+		}
+	}
+	// the subtype can only be checked against a valid file type
+	if fileSubtypeObj, ok := versionJson["fileSubtype"]; ok && fileTypeValid {
+		if fileSubtype, err := parseFileSubtype(fileSubtypeObj, fixedFileInfo.FileType); err != nil {
+			errs.add(err)
+		} else {
+			fixedFileInfo.FileSubtype = fileSubtype
+		}
+	} else if !ok && fixedFileInfo.FileType == VFT_VXD {
+		errs.add(fieldError("fileSubtype", "is required for file type VFT_VXD"))
+	}
+	if fileDateObj, ok := versionJson["fileDate"]; ok {
+		_, hasMS := versionJson["fileDateMS"]
+		_, hasLS := versionJson["fileDateLS"]
+		if hasMS || hasLS {
+			errs.add(fieldError("fileDate", "cannot be combined with fileDateMS or fileDateLS"))
+		} else if ms, ls, err := parseFileDate(fileDateObj); err != nil {
+			errs.add(err)
+		} else {
+			fixedFileInfo.FileDateMS = ms
+			fixedFileInfo.FileDateLS = ls
+		}
+	}
+	if fileDateMSObj, ok := versionJson["fileDateMS"]; ok {
+		if fileDateMS, err := parseInteger(fileDateMSObj, "fileDateMS", 0, math.MaxUint32); err != nil {
+			errs.add(err)
+		} else {
+			fixedFileInfo.FileDateMS = uint32(fileDateMS)
+		}
+	}
+	if fileDateLSObj, ok := versionJson["fileDateLS"]; ok {
+		if fileDateLS, err := parseInteger(fileDateLSObj, "fileDateLS", 0, math.MaxUint32); err != nil {
+			errs.add(err)
+		} else {
+			fixedFileInfo.FileDateLS = uint32(fileDateLS)
+		}
+	}
+	stringFileInfo, err := parseStringFileInfo(versionJson, defaultStrings)
+	if err != nil {
+		errs.add(err)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &Resource{
+		Type: ResourceTypeVersion,
+		Id:   1,
+		Data: EncodeVersionInfo(&fixedFileInfo, language, 1200, stringFileInfo),
+	}, nil
+}
+
+// parseStringFileInfo reads the stringFileInfo field of the version JSON.  Standard strings that are not given are
+// taken from defaultStrings.
+func parseStringFileInfo(
+	versionJson map[string]interface{},
+	defaultStrings map[string]string) ([]VersionString, error) {
+	stringFileInfo := make([]VersionString, 0, 10)
+	stringFileInfoObj, ok := versionJson["stringFileInfo"]
+	if !ok {
+		stringFileInfoObj = map[string]interface{}{}
+	}
+	stringFileInfoJson, ok := stringFileInfoObj.(map[string]interface{})
+	if !ok {
+		return nil, fieldError("stringFileInfo", "must specify an object")
+	}
+	var errs ErrorList
+	for key := range stringFileInfoJson {
+		valid := key == "custom"
+		for _, field := range stringFileInfoFields {
+			valid = valid || key == field.JsonName
+		}
+		if !valid {
+			errs.add(atField(key, errors.New(fmt.Sprintf("invalid string %s; use custom for other strings", key))))
+		}
+	}
+	for _, field := range stringFileInfoFields {
+		fieldValObj, ok := stringFileInfoJson[field.JsonName]
+		if !ok {
+			if defaultVal, ok := defaultStrings[field.JsonName]; ok {
+				fieldValObj = defaultVal
+			} else {
+				continue
+			}
+		}
+		if fieldVal, ok := fieldValObj.(string); ok {
+			stringFileInfo = append(stringFileInfo, VersionString{
+				Key:   field.WinName,
+				Value: fieldVal,
+			})
+		} else {
+			errs.add(fieldError(field.JsonName, "must specify a string"))
+		}
+	}
+	if customObj, ok := stringFileInfoJson["custom"]; ok {
+		if customJson, ok := customObj.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(customJson))
+			for key := range customJson {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if value, ok := customJson[key].(string); ok {
+					stringFileInfo = append(stringFileInfo, VersionString{
+						Key:   key,
+						Value: value,
+					})
+				} else {
+					errs.add(atField("custom", fieldError(key, "must specify a string")))
+				}
+			}
+		} else {
+			errs.add(fieldError("custom", "must specify an object"))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, atField("stringFileInfo", errs)
+	}
+	return stringFileInfo, nil
+}
+
+func parseMessageSeverity(severityObj interface{}) (uint32, error) {
+	severityName, ok := severityObj.(string)
+	if !ok {
+		return 0, fieldError("severity", "must specify a string")
+	}
+	severity, ok := lookupNamedValue(messageSeverityNames, severityName)
+	if !ok {
+		return 0, atField("severity", errors.New(fmt.Sprintf("invalid severity: %s", severityName)))
+	}
+	return severity, nil
+}
+
+func parseMessageTableResource(messageTableJson []interface{}) (*Resource, error) {
+	messages := make(map[uint32]string)
+	var errs ErrorList
+	for i, messageObj := range messageTableJson {
+		elementPath := fmt.Sprintf("/%d", i)
+		messageJson, ok := messageObj.(map[string]interface{})
+		if !ok {
+			errs.add(withPath(errors.New("message must specify an object"), elementPath))
+			continue
+		}
+		var messageErrs ErrorList
+		var id uint32
+		if idObj, ok := messageJson["id"]; !ok {
+			messageErrs.add(fieldError("id", "is required"))
+		} else if idValue, err := parseInteger(idObj, "id", 0, math.MaxUint32); err != nil {
+			messageErrs.add(err)
+		} else {
+			id = uint32(idValue)
+		}
+		if severityObj, ok := messageJson["severity"]; !ok {
+			messageErrs.add(fieldError("severity", "is required"))
+		} else if severity, err := parseMessageSeverity(severityObj); err != nil {
+			messageErrs.add(err)
+		} else {
+			id |= severity
+		}
+		messageText := ""
+		if messageTextObj, ok := messageJson["messageText"]; !ok {
+			messageErrs.add(fieldError("messageText", "is required"))
+		} else if messageText, ok = messageTextObj.(string); !ok {
+			messageErrs.add(fieldError("messageText", "must specify a string"))
+		}
+		if len(messageErrs) > 0 {
+			errs.add(withPath(messageErrs, elementPath))
+			continue
+		}
+		if _, ok := messages[id]; ok {
+			errs.add(withPath(errors.New(fmt.Sprintf("duplicate message with ID %x", id)), elementPath))
+			continue
+		}
+		messages[id] = messageText
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &Resource{
+		Type: ResourceTypeMessageTable,
+		Id:   1,
+		Data: EncodeMessageTable(messages),
+	}, nil
+}
+
+func parseStringTableResources(stringTableJson []interface{}) ([]*Resource, error) {
+	texts := make(map[uint16]string)
+	var errs ErrorList
+	for i, stringObj := range stringTableJson {
+		elementPath := fmt.Sprintf("/%d", i)
+		stringJson, ok := stringObj.(map[string]interface{})
+		if !ok {
+			errs.add(withPath(errors.New("string must specify an object"), elementPath))
+			continue
+		}
+		var stringErrs ErrorList
+		var id uint16
+		if idObj, ok := stringJson["id"]; !ok {
+			stringErrs.add(fieldError("id", "is required"))
+		} else if idValue, err := parseInteger(idObj, "id", 0, 0xFFFF); err != nil {
+			stringErrs.add(err)
+		} else {
+			id = uint16(idValue)
+		}
+		text := ""
+		if textObj, ok := stringJson["text"]; !ok {
+			stringErrs.add(fieldError("text", "is required"))
+		} else if text, ok = textObj.(string); !ok {
+			stringErrs.add(fieldError("text", "must specify a string"))
+		} else if text == "" {
+			// a string table stores missing strings as empty ones, so an empty string could not be told apart
+			stringErrs.add(fieldError("text", "must not be empty"))
+		} else if len(utf16.Encode([]rune(text))) > 0xFFFF {
+			stringErrs.add(fieldError("text", "must be shorter than 65536 UTF-16 code units"))
+		}
+		if len(stringErrs) > 0 {
+			errs.add(withPath(stringErrs, elementPath))
+			continue
+		}
+		if _, ok := texts[id]; ok {
+			errs.add(withPath(atField("id", errors.New(fmt.Sprintf("duplicate string with ID %d", id))), elementPath))
+			continue
+		}
+		texts[id] = text
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return EncodeStringTable(texts), nil
+}
+
+func parseRCDataResources(rcdataListJson []interface{}, sourceDir string) ([]*Resource, error) {
+	resources := make([]*Resource, 0, len(rcdataListJson))
+	seen := make(map[string]bool)
+	var errs ErrorList
+	for i, rcdataObj := range rcdataListJson {
+		elementPath := fmt.Sprintf("/%d", i)
+		rcdataJson, ok := rcdataObj.(map[string]interface{})
+		if !ok {
+			errs.add(withPath(errors.New("RCDATA resource must specify an object"), elementPath))
+			continue
+		}
+		var rcdataErrs ErrorList
+		res := &Resource{Type: ResourceTypeRCData}
+		idObj, hasId := rcdataJson["id"]
+		nameObj, hasName := rcdataJson["name"]
+		if hasId && hasName {
+			rcdataErrs.add(fieldError("name", "cannot be combined with id"))
+		} else if hasName {
+			if res.Name, ok = nameObj.(string); !ok || res.Name == "" {
+				rcdataErrs.add(fieldError("name", "must specify a non-empty string"))
+			}
+		} else if !hasId {
+			rcdataErrs.add(fieldError("id", "is required unless name is given"))
+		} else if id, err := parseResourceId(idObj); err != nil {
+			rcdataErrs.add(err)
+		} else {
+			res.Id = id
+		}
+		fileObj, hasFile := rcdataJson["file"]
+		textObj, hasText := rcdataJson["text"]
+		if hasFile && hasText {
+			rcdataErrs.add(fieldError("text", "cannot be combined with file"))
+		} else if hasText {
+			if text, ok := textObj.(string); ok {
+				res.Data = []byte(text)
+			} else {
+				rcdataErrs.add(fieldError("text", "must specify a string"))
+			}
+		} else if !hasFile {
+			rcdataErrs.add(fieldError("file", "is required unless text is given"))
+		} else if fileName, ok := fileObj.(string); !ok {
+			rcdataErrs.add(fieldError("file", "must specify a file name"))
+		} else if data, err := ioutil.ReadFile(resolveFileName(sourceDir, fileName)); err != nil {
+			rcdataErrs.add(atField("file", errors.New(fmt.Sprintf("could not read file '%s'", fileName))))
+		} else {
+			res.Data = data
+		}
+		if len(rcdataErrs) > 0 {
+			errs.add(withPath(rcdataErrs, elementPath))
+			continue
+		}
+		key := fmt.Sprintf("%d", res.Id)
+		if res.Name != "" {
+			key = strings.ToUpper(res.Name)
+		}
+		if seen[key] {
+			err := errors.New(fmt.Sprintf("duplicate RCDATA resource %s", key))
+			errs.add(withPath(err, elementPath))
+			continue
+		}
+		seen[key] = true
+		resources = append(resources, res)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return resources, nil
+}
+
+func parseInteger(obj interface{}, fieldName string, min int64, max int64) (int64, error) {
+	value, ok := obj.(int64)
+	if !ok {
+		return 0, fieldError(fieldName, "must specify an integer")
+	}
+	if value < min || value > max {
+		return 0, fieldError(fieldName, "must be between %d and %d", min, max)
+	}
+	return value, nil
+}
+
+func parseResourceId(obj interface{}) (uint, error) {
+	id, err := parseInteger(obj, "id", 1, 0xFFFF)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+func parseStyle(styleObj interface{}, fieldName string, styleNames map[string]uint32) (uint32, error) {
+	if _, ok := styleObj.(int64); ok {
+		style, err := parseInteger(styleObj, fieldName, 0, math.MaxUint32)
+		return uint32(style), err
+	}
+	styleArray, ok := styleObj.([]interface{})
+	if !ok {
+		return 0, fieldError(fieldName, "must specify a list of strings or an integer")
+	}
+	var style uint32
+	var errs ErrorList
+	for i, styleNameObj := range styleArray {
+		styleName, ok := styleNameObj.(string)
+		if !ok {
+			return 0, fieldError(fieldName, "must specify a list of strings or an integer")
+		}
+		if value, ok := styleNames[styleName]; ok {
+			style |= value
+		} else {
+			errs.add(withPath(errors.New(fmt.Sprintf("invalid style: %s", styleName)), fmt.Sprintf("/%d", i)))
+		}
+	}
+	if len(errs) > 0 {
+		return 0, atField(fieldName, errs)
+	}
+	return style, nil
+}
+
+func parseNameOrOrdinal(obj interface{}, fieldName string) (NameOrOrdinal, error) {
+	if name, ok := obj.(string); ok {
+		return NameOrOrdinal{Name: name}, nil
+	}
+	ordinal, err := parseInteger(obj, fieldName, 1, 0xFFFF)
+	if err != nil {
+		return NameOrOrdinal{}, fieldError(fieldName, "must specify a string or an integer")
+	}
+	return NameOrOrdinal{Ordinal: uint16(ordinal)}, nil
+}
+
+func parseDialogRect(rectObj interface{}) (DialogRect, error) {
+	rectArray, ok := rectObj.([]interface{})
+	if !ok || len(rectArray) != 4 {
+		return DialogRect{}, fieldError("rect", "must specify a list of four integers")
+	}
+	var values [4]int16
+	for i, valueObj := range rectArray {
+		value, ok := valueObj.(int64)
+		if !ok || value < math.MinInt16 || value > math.MaxInt16 {
+			err := errors.New(fmt.Sprintf("field rect must specify integers between %d and %d",
+				math.MinInt16, math.MaxInt16))
+			return DialogRect{}, atField("rect", withPath(err, fmt.Sprintf("/%d", i)))
+		}
+		values[i] = int16(value)
+	}
+	return DialogRect{X: values[0], Y: values[1], CX: values[2], CY: values[3]}, nil
+}
+
+func parseDialogFont(fontObj interface{}) (*DialogFont, error) {
+	fontJson, ok := fontObj.(map[string]interface{})
+	if !ok {
+		return nil, fieldError("font", "must specify an object")
+	}
+	font := DialogFont{
+		PointSize: 8,
+		Weight:    400,
+		Charset:   1,
+	}
+	var errs ErrorList
+	if typefaceObj, ok := fontJson["typeface"]; !ok {
+		errs.add(fieldError("typeface", "is required"))
+	} else if font.Typeface, ok = typefaceObj.(string); !ok {
+		errs.add(fieldError("typeface", "must specify a string"))
+	}
+	if pointSizeObj, ok := fontJson["pointSize"]; ok {
+		if pointSize, err := parseInteger(pointSizeObj, "pointSize", 1, 0xFFFF); err != nil {
+			errs.add(err)
+		} else {
+			font.PointSize = uint16(pointSize)
+		}
+	}
+	if weightObj, ok := fontJson["weight"]; ok {
+		if weight, err := parseInteger(weightObj, "weight", 0, 1000); err != nil {
+			errs.add(err)
+		} else {
+			font.Weight = uint16(weight)
+		}
+	}
+	if italicObj, ok := fontJson["italic"]; ok {
+		if font.Italic, ok = italicObj.(bool); !ok {
+			errs.add(fieldError("italic", "must specify a boolean"))
+		}
+	}
+	if charsetObj, ok := fontJson["charset"]; ok {
+		if charset, err := parseInteger(charsetObj, "charset", 0, 0xFF); err != nil {
+			errs.add(err)
+		} else {
+			font.Charset = uint8(charset)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, atField("font", errs)
+	}
+	return &font, nil
+}
+
+func parseDialogControl(controlObj interface{}) (*DialogControl, error) {
+	controlJson, ok := controlObj.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("control must specify an object")
+	}
+	control := DialogControl{
+		Style: WS_CHILD | WS_VISIBLE,
+	}
+	var errs ErrorList
+	if classObj, ok := controlJson["class"]; !ok {
+		errs.add(fieldError("class", "is required"))
+	} else if class, err := parseNameOrOrdinal(classObj, "class"); err != nil {
+		errs.add(err)
+	} else if ordinal, ok := dialogClassOrdinals[strings.ToUpper(class.Name)]; ok {
+		control.Class = NameOrOrdinal{Ordinal: ordinal}
+	} else {
+		control.Class = class
+	}
+	if textObj, ok := controlJson["text"]; ok {
+		if text, err := parseNameOrOrdinal(textObj, "text"); err != nil {
+			errs.add(err)
+		} else {
+			control.Text = text
+		}
+	}
+	if idObj, ok := controlJson["id"]; ok {
+		if id, err := parseInteger(idObj, "id", -1, math.MaxUint32); err != nil {
+			errs.add(err)
+		} else {
+			control.Id = uint32(id)
+		}
+	}
+	if rectObj, ok := controlJson["rect"]; !ok {
+		errs.add(fieldError("rect", "is required"))
+	} else if rect, err := parseDialogRect(rectObj); err != nil {
+		errs.add(err)
+	} else {
+		control.Rect = rect
+	}
+	if styleObj, ok := controlJson["style"]; ok {
+		if style, err := parseStyle(styleObj, "style", windowStyles); err != nil {
+			errs.add(err)
+		} else {
+			control.Style |= style
+		}
+	}
+	if exStyleObj, ok := controlJson["exStyle"]; ok {
+		if exStyle, err := parseStyle(exStyleObj, "exStyle", windowExStyles); err != nil {
+			errs.add(err)
+		} else {
+			control.ExStyle = exStyle
+		}
+	}
+	if helpIdObj, ok := controlJson["helpId"]; ok {
+		if helpId, err := parseInteger(helpIdObj, "helpId", 0, math.MaxUint32); err != nil {
+			errs.add(err)
+		} else {
+			control.HelpId = uint32(helpId)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &control, nil
+}
+
+func parseDialogResource(dialogObj interface{}) (*Resource, error) {
+	dialogJson, ok := dialogObj.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("dialog must specify an object")
+	}
+	var errs ErrorList
+	var id uint
+	if idObj, ok := dialogJson["id"]; !ok {
+		errs.add(fieldError("id", "is required"))
+	} else if resourceId, err := parseResourceId(idObj); err != nil {
+		errs.add(err)
+	} else {
+		id = resourceId
+	}
+	var err error
+	dialog := Dialog{
+		Style: windowStyles["WS_POPUP"] | windowStyles["WS_BORDER"] | windowStyles["WS_SYSMENU"],
+	}
+	if styleObj, ok := dialogJson["style"]; ok {
+		if dialog.Style, err = parseStyle(styleObj, "style", windowStyles); err != nil {
+			errs.add(err)
+		}
+	}
+	if exStyleObj, ok := dialogJson["exStyle"]; ok {
+		if dialog.ExStyle, err = parseStyle(exStyleObj, "exStyle", windowExStyles); err != nil {
+			errs.add(err)
+		}
+	}
+	if rectObj, ok := dialogJson["rect"]; !ok {
+		errs.add(fieldError("rect", "is required"))
+	} else if dialog.Rect, err = parseDialogRect(rectObj); err != nil {
+		errs.add(err)
+	}
+	if helpIdObj, ok := dialogJson["helpId"]; ok {
+		if helpId, err := parseInteger(helpIdObj, "helpId", 0, math.MaxUint32); err != nil {
+			errs.add(err)
+		} else {
+			dialog.HelpId = uint32(helpId)
+		}
+	}
+	if captionObj, ok := dialogJson["caption"]; ok {
+		if dialog.Caption, ok = captionObj.(string); !ok {
+			errs.add(fieldError("caption", "must specify a string"))
+		}
+	}
+	if menuObj, ok := dialogJson["menu"]; ok {
+		if dialog.Menu, err = parseNameOrOrdinal(menuObj, "menu"); err != nil {
+			errs.add(err)
+		}
+	}
+	if classObj, ok := dialogJson["class"]; ok {
+		if dialog.Class, err = parseNameOrOrdinal(classObj, "class"); err != nil {
+			errs.add(err)
+		}
+	}
+	if fontObj, ok := dialogJson["font"]; ok {
+		if dialog.Font, err = parseDialogFont(fontObj); err != nil {
+			errs.add(err)
+		}
+	} else if dialog.Style&DS_SETFONT != 0 {
+		errs.add(fieldError("font", "is required when style includes DS_SETFONT"))
+	}
+	if controlsObj, ok := dialogJson["controls"]; ok {
+		if controlsArray, ok := controlsObj.([]interface{}); !ok {
+			errs.add(fieldError("controls", "must specify a list of objects"))
+		} else if len(controlsArray) > 0xFFFF {
+			errs.add(fieldError("controls", "must specify at most 65535 controls"))
+		} else {
+			for i, controlObj := range controlsArray {
+				if control, err := parseDialogControl(controlObj); err != nil {
+					errs.add(atField("controls", withPath(err, fmt.Sprintf("/%d", i))))
+				} else {
+					dialog.Controls = append(dialog.Controls, *control)
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &Resource{
+		Type: ResourceTypeDialog,
+		Id:   id,
+		Data: EncodeDialog(&dialog),
+	}, nil
+}
+
+func parseDialogResources(dialogsJson []interface{}) ([]*Resource, error) {
+	resources := make([]*Resource, 0, len(dialogsJson))
+	ids := make(map[uint]bool)
+	var errs ErrorList
+	for i, dialogObj := range dialogsJson {
+		elementPath := fmt.Sprintf("/%d", i)
+		dialogRes, err := parseDialogResource(dialogObj)
+		if err != nil {
+			errs.add(withPath(err, elementPath))
+			continue
+		}
+		if ids[dialogRes.Id] {
+			err := errors.New(fmt.Sprintf("duplicate dialog with ID %d", dialogRes.Id))
+			errs.add(withPath(atField("id", err), elementPath))
+			continue
+		}
+		ids[dialogRes.Id] = true
+		resources = append(resources, dialogRes)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return resources, nil
+}
+
+var menuItemFlags = []struct {
+	JsonName   string
+	MenuExOnly bool
+}{
+	{JsonName: "separator"},
+	{JsonName: "checked"},
+	{JsonName: "grayed"},
+	{JsonName: "disabled"},
+	{JsonName: "default", MenuExOnly: true},
+	{JsonName: "radioCheck", MenuExOnly: true},
+	{JsonName: "rightJustify"},
+	{JsonName: "menuBreak"},
+	{JsonName: "menuBarBreak"},
+}
+
+func parseMenuItems(itemsObj interface{}, menuEx bool) ([]MenuItem, error) {
+	itemsArray, ok := itemsObj.([]interface{})
+	if !ok {
+		return nil, fieldError("items", "must specify a list of objects")
+	}
+	items := make([]MenuItem, 0, len(itemsArray))
+	var errs ErrorList
+	for i, itemObj := range itemsArray {
+		elementPath := fmt.Sprintf("/%d", i)
+		itemJson, ok := itemObj.(map[string]interface{})
+		if !ok {
+			errs.add(atField("items", withPath(errors.New("menu item must specify an object"), elementPath)))
+			continue
+		}
+		var item MenuItem
+		var itemErrs ErrorList
+		flags := make(map[string]bool)
+		for _, flag := range menuItemFlags {
+			if flagObj, ok := itemJson[flag.JsonName]; ok {
+				flagValue, ok := flagObj.(bool)
+				if !ok {
+					itemErrs.add(fieldError(flag.JsonName, "must specify a boolean"))
+				} else if flagValue && flag.MenuExOnly && !menuEx {
+					itemErrs.add(fieldError(flag.JsonName, "is only supported in MENUEX format"))
+				}
+				flags[flag.JsonName] = flagValue
+			}
+		}
+		item.Separator = flags["separator"]
+		item.Checked = flags["checked"]
+		item.Grayed = flags["grayed"]
+		item.Disabled = flags["disabled"]
+		item.Default = flags["default"]
+		item.RadioCheck = flags["radioCheck"]
+		item.RightJustify = flags["rightJustify"]
+		item.MenuBreak = flags["menuBreak"]
+		item.MenuBarBreak = flags["menuBarBreak"]
+		if textObj, ok := itemJson["text"]; ok {
+			if item.Text, ok = textObj.(string); !ok {
+				itemErrs.add(fieldError("text", "must specify a string"))
+			}
+		} else if !item.Separator {
+			itemErrs.add(fieldError("text", "is required"))
+		}
+		if idObj, ok := itemJson["id"]; ok {
+			maxId := int64(math.MaxUint32)
+			if !menuEx {
+				maxId = 0xFFFF
+			}
+			if id, err := parseInteger(idObj, "id", 0, maxId); err != nil {
+				itemErrs.add(err)
+			} else {
+				item.Id = uint32(id)
+			}
+		}
+		if helpIdObj, ok := itemJson["helpId"]; ok {
+			if !menuEx {
+				itemErrs.add(fieldError("helpId", "is only supported in MENUEX format"))
+			} else if helpId, err := parseInteger(helpIdObj, "helpId", 0, math.MaxUint32); err != nil {
+				itemErrs.add(err)
+			} else {
+				item.HelpId = uint32(helpId)
+			}
+		}
+		if subItemsObj, ok := itemJson["items"]; ok {
+			if item.Separator {
+				itemErrs.add(atField("items", errors.New("a separator cannot contain items")))
+			} else if subItems, err := parseMenuItems(subItemsObj, menuEx); err != nil {
+				itemErrs.add(err)
+			} else {
+				item.Popup = true
+				item.Items = subItems
+			}
+		} else if item.HelpId != 0 {
+			itemErrs.add(fieldError("helpId", "may only be specified for popup menus"))
+		}
+		if len(itemErrs) > 0 {
+			errs.add(atField("items", withPath(itemErrs, elementPath)))
+			continue
+		}
+		items = append(items, item)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return items, nil
+}
+
+func parseMenuResource(menuObj interface{}) (*Resource, error) {
+	menuJson, ok := menuObj.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("menu must specify an object")
+	}
+	var errs ErrorList
+	var id uint
+	if idObj, ok := menuJson["id"]; !ok {
+		errs.add(fieldError("id", "is required"))
+	} else if resourceId, err := parseResourceId(idObj); err != nil {
+		errs.add(err)
+	} else {
+		id = resourceId
+	}
+	menuEx := true
+	if formatObj, ok := menuJson["format"]; ok {
+		switch formatObj {
+		case "menuex":
+			menuEx = true
+		case "menu":
+			menuEx = false
+		default:
+			errs.add(fieldError("format", "must specify \"menu\" or \"menuex\""))
+		}
+	}
+	var menu Menu
+	if helpIdObj, ok := menuJson["helpId"]; ok {
+		if !menuEx {
+			errs.add(fieldError("helpId", "is only supported in MENUEX format"))
+		} else if helpId, err := parseInteger(helpIdObj, "helpId", 0, math.MaxUint32); err != nil {
+			errs.add(err)
+		} else {
+			menu.HelpId = uint32(helpId)
+		}
+	}
+	if itemsObj, ok := menuJson["items"]; !ok {
+		errs.add(fieldError("items", "is required"))
+	} else if items, err := parseMenuItems(itemsObj, menuEx); err != nil {
+		errs.add(err)
+	} else {
+		menu.Items = items
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	var data []byte
+	if menuEx {
+		data = EncodeMenuEx(&menu)
+	} else {
+		data = EncodeMenu(&menu)
+	}
+	return &Resource{
+		Type: ResourceTypeMenu,
+		Id:   id,
+		Data: data,
+	}, nil
+}
+
+func parseMenuResources(menusJson []interface{}) ([]*Resource, error) {
+	resources := make([]*Resource, 0, len(menusJson))
+	ids := make(map[uint]bool)
+	var errs ErrorList
+	for i, menuObj := range menusJson {
+		elementPath := fmt.Sprintf("/%d", i)
+		menuRes, err := parseMenuResource(menuObj)
+		if err != nil {
+			errs.add(withPath(err, elementPath))
+			continue
+		}
+		if ids[menuRes.Id] {
+			err := errors.New(fmt.Sprintf("duplicate menu with ID %d", menuRes.Id))
+			errs.add(withPath(atField("id", err), elementPath))
+			continue
+		}
+		ids[menuRes.Id] = true
+		resources = append(resources, menuRes)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return resources, nil
+}
+
+func parseAcceleratorResource(tableObj interface{}) (*Resource, error) {
+	tableJson, ok := tableObj.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("accelerator table must specify an object")
+	}
+	var errs ErrorList
+	var id uint
+	if idObj, ok := tableJson["id"]; !ok {
+		errs.add(fieldError("id", "is required"))
+	} else if resourceId, err := parseResourceId(idObj); err != nil {
+		errs.add(err)
+	} else {
+		id = resourceId
+	}
+	var entriesArray []interface{}
+	if entriesObj, ok := tableJson["entries"]; !ok {
+		errs.add(fieldError("entries", "is required"))
+	} else if entriesArray, ok = entriesObj.([]interface{}); !ok || len(entriesArray) == 0 {
+		errs.add(fieldError("entries", "must specify a non-empty list of objects"))
+	}
+	entries := make([]AcceleratorEntry, 0, len(entriesArray))
+	chords := make(map[uint32]string)
+	for i, entryObj := range entriesArray {
+		elementPath := fmt.Sprintf("/%d", i)
+		entryJson, ok := entryObj.(map[string]interface{})
+		if !ok {
+			errs.add(atField("entries", withPath(errors.New("accelerator must specify an object"), elementPath)))
+			continue
+		}
+		var entryErrs ErrorList
+		var flags, key uint16
+		if keyObj, ok := entryJson["key"]; !ok {
+			entryErrs.add(fieldError("key", "is required"))
+		} else if chord, ok := keyObj.(string); !ok {
+			entryErrs.add(fieldError("key", "must specify a string"))
+		} else if chordFlags, chordKey, err := ParseKeyChord(chord); err != nil {
+			entryErrs.add(atField("key", err))
+		} else {
+			chordId := uint32(chordFlags)<<16 | uint32(chordKey)
+			if otherChord, ok := chords[chordId]; ok {
+				entryErrs.add(atField("key", errors.New(fmt.Sprintf("duplicate key chord %s (same as %s)",
+					chord, otherChord))))
+			} else {
+				chords[chordId] = chord
+			}
+			flags, key = chordFlags, chordKey
+		}
+		var command int64
+		if commandObj, ok := entryJson["command"]; !ok {
+			entryErrs.add(fieldError("command", "is required"))
+		} else if value, err := parseInteger(commandObj, "command", 0, 0xFFFF); err != nil {
+			entryErrs.add(err)
+		} else {
+			command = value
+		}
+		if noInvertObj, ok := entryJson["noInvert"]; ok {
+			if noInvert, ok := noInvertObj.(bool); !ok {
+				entryErrs.add(fieldError("noInvert", "must specify a boolean"))
+			} else if noInvert {
+				flags |= FNOINVERT
+			}
+		}
+		if len(entryErrs) > 0 {
+			errs.add(atField("entries", withPath(entryErrs, elementPath)))
+			continue
+		}
+		entries = append(entries, AcceleratorEntry{
+			Flags:   flags,
+			Key:     key,
+			Command: uint16(command),
+		})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &Resource{
+		Type: ResourceTypeAccelerator,
+		Id:   id,
+		Data: EncodeAcceleratorTable(entries),
+	}, nil
+}
+
+func parseAcceleratorResources(tablesJson []interface{}) ([]*Resource, error) {
+	resources := make([]*Resource, 0, len(tablesJson))
+	ids := make(map[uint]bool)
+	var errs ErrorList
+	for i, tableObj := range tablesJson {
+		elementPath := fmt.Sprintf("/%d", i)
+		tableRes, err := parseAcceleratorResource(tableObj)
+		if err != nil {
+			errs.add(withPath(err, elementPath))
+			continue
+		}
+		if ids[tableRes.Id] {
+			err := errors.New(fmt.Sprintf("duplicate accelerator table with ID %d", tableRes.Id))
+			errs.add(withPath(atField("id", err), elementPath))
+			continue
+		}
+		ids[tableRes.Id] = true
+		resources = append(resources, tableRes)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return resources, nil
+}
+
+func loadManifestResource(manifestFileName string) (*Resource, error) {
+	f, err := os.Open(manifestFileName)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("could not open file '%s'", manifestFileName))
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("could not read file '%s'", manifestFileName))
+	}
+	if err := ValidateManifest(data); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid manifest file '%s' (%s)", manifestFileName, err))
+	}
+	return &Resource{
+		Type: ResourceTypeManifest,
+		Data: data,
+	}, nil
+}
+
+func loadFontResources(fontsJson []interface{}, sourceDir string) ([]*Resource, error) {
+	resources := make([]*Resource, 0, len(fontsJson))
+	fontDir := make([]FontDirEntry, 0)
+	ids := make(map[uint]bool)
+	addFont := func(id uint, data []byte) error {
+		if ids[id] {
+			return atField("id", errors.New(fmt.Sprintf("duplicate font with ID %d", id)))
+		}
+		ids[id] = true
+		resources = append(resources, &Resource{
+			Type: ResourceTypeFont,
+			Id:   id,
+			Data: data,
+		})
+		return nil
+	}
+	var errs ErrorList
+	for i, fontObj := range fontsJson {
+		if err := loadFont(fontObj, sourceDir, addFont, &fontDir); err != nil {
+			errs.add(withPath(err, fmt.Sprintf("/%d", i)))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(fontDir) > 0 {
+		resources = append(resources, &Resource{
+			Type: ResourceTypeFontDir,
+			Name: "FONTDIR",
+			Data: EncodeFontDir(fontDir),
+		})
+	}
+	return resources, nil
+}
+
+// loadFont reads one entry of the fonts list, passing each font it contains to addFont and recording bitmap and
+// vector fonts in the font directory.
+func loadFont(
+	fontObj interface{},
+	sourceDir string,
+	addFont func(id uint, data []byte) error,
+	fontDir *[]FontDirEntry) error {
+	fontJson, ok := fontObj.(map[string]interface{})
+	if !ok {
+		return errors.New("font must specify an object")
+	}
+	var errs ErrorList
+	var id uint
+	if idObj, ok := fontJson["id"]; !ok {
+		errs.add(fieldError("id", "is required"))
+	} else if resourceId, err := parseResourceId(idObj); err != nil {
+		errs.add(err)
+	} else {
+		id = resourceId
+	}
+	var fileName string
+	if fileObj, ok := fontJson["file"]; !ok {
+		errs.add(fieldError("file", "is required"))
+	} else if fileName, ok = fileObj.(string); !ok {
+		errs.add(fieldError("file", "must specify a file name"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	data, err := ioutil.ReadFile(resolveFileName(sourceDir, fileName))
+	if err != nil {
+		return atField("file", errors.New(fmt.Sprintf("could not read file '%s'", fileName)))
+	}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".ttf", ".otf", ".ttc":
+		if !IsOpenTypeFont(data) {
+			return atField("file", errors.New(fmt.Sprintf("file '%s' is not a TrueType or OpenType font", fileName)))
+		}
+		return addFont(id, data)
+	case ".fnt", ".fon":
+		fonts := [][]byte{data}
+		if strings.ToLower(filepath.Ext(fileName)) == ".fon" {
+			if fonts, err = ExtractFONFonts(data); err != nil {
+				return atField("file", errors.New(fmt.Sprintf("invalid font file '%s' (%s)", fileName, err)))
+			}
+		}
+		for i, font := range fonts {
+			entry, err := ParseFNT(font)
+			if err != nil {
+				return atField("file", errors.New(fmt.Sprintf("invalid font file '%s' (%s)", fileName, err)))
+			}
+			if id+uint(i) > 0xFFFF {
+				return atField("id", errors.New(fmt.Sprintf("too many fonts in file '%s' for ID %d", fileName, id)))
+			}
+			entry.Ordinal = uint16(id + uint(i))
+			if err := addFont(id+uint(i), font); err != nil {
+				return err
+			}
+			*fontDir = append(*fontDir, *entry)
+		}
+		return nil
+	default:
+		return atField("file", errors.New(fmt.Sprintf("unsupported font file type: %s", fileName)))
+	}
+}
+
+// Settings that may be given in a manifest object, and the values accepted for the string settings.  Values are
+// compared case-insensitively.
+var (
+	manifestSettingNames = []string{
+		"assemblyIdentity", "requestedExecutionLevel", "uiAccess", "dpiAware", "dpiAwareness", "longPathAware",
+		"activeCodePage", "supportedOS", "commonControls", "heapType",
+	}
+	processorArchitectureNames = []string{"x86", "amd64", "arm", "arm64", "ia64", "msil", "*"}
+	executionLevelNames        = []string{"asInvoker", "highestAvailable", "requireAdministrator"}
+	dpiAwareNames              = []string{"true", "false", "true/pm", "per monitor"}
+	dpiAwarenessNames          = []string{"Unaware", "System", "PerMonitor", "PerMonitorV2"}
+	activeCodePageNames        = []string{"UTF-8", "Legacy"}
+	heapTypeNames              = []string{"SegmentHeap"}
+)
+
+func parseManifestBoolean(manifestJson map[string]interface{}, fieldName string) (bool, error) {
+	valueObj, ok := manifestJson[fieldName]
+	if !ok {
+		return false, nil
+	}
+	value, ok := valueObj.(bool)
+	if !ok {
+		return false, fieldError(fieldName, "must specify a boolean")
+	}
+	return value, nil
+}
+
+func parseManifestString(manifestJson map[string]interface{}, fieldName string, allowed ...string) (string, error) {
+	valueObj, ok := manifestJson[fieldName]
+	if !ok {
+		return "", nil
+	}
+	value, ok := valueObj.(string)
+	if !ok {
+		return "", fieldError(fieldName, "must specify a string")
+	}
+	if len(allowed) == 0 {
+		return value, nil
+	}
+	for _, allowedValue := range allowed {
+		if strings.EqualFold(value, allowedValue) {
+			return allowedValue, nil
+		}
+	}
+	return "", atField(fieldName, errors.New(fmt.Sprintf("invalid %s: %s", fieldName, value)))
+}
+
+func parseManifest(manifestJson map[string]interface{}) (*Manifest, error) {
+	var manifest Manifest
+	var errs ErrorList
+	var err error
+	for key := range manifestJson {
+		valid := false
+		for _, settingName := range manifestSettingNames {
+			valid = valid || key == settingName
+		}
+		if !valid {
+			errs.add(atField(key, errors.New(fmt.Sprintf("invalid manifest setting %s", key))))
+		}
+	}
+	if identityObj, ok := manifestJson["assemblyIdentity"]; ok {
+		if identityJson, ok := identityObj.(map[string]interface{}); !ok {
+			errs.add(fieldError("assemblyIdentity", "must specify an object"))
+		} else {
+			var identity AssemblyIdentity
+			var identityErrs ErrorList
+			if identity.Name, err = parseManifestString(identityJson, "name"); err != nil {
+				identityErrs.add(err)
+			} else if identity.Name == "" {
+				identityErrs.add(fieldError("name", "is required"))
+			}
+			if identity.Version, err = parseManifestString(identityJson, "version"); err != nil {
+				identityErrs.add(err)
+			} else if _, err := parseFileVersionNumber(identity.Version); err != nil {
+				identityErrs.add(atField("version", errors.New(fmt.Sprintf("invalid version number: %s",
+					identity.Version))))
+			}
+			identity.ProcessorArchitecture, err = parseManifestString(identityJson, "processorArchitecture",
+				processorArchitectureNames...)
+			if err != nil {
+				identityErrs.add(err)
+			}
+			if len(identityErrs) > 0 {
+				errs.add(atField("assemblyIdentity", identityErrs))
+			}
+			manifest.Identity = &identity
+		}
+	}
+	manifest.RequestedExecutionLevel, err = parseManifestString(manifestJson, "requestedExecutionLevel",
+		executionLevelNames...)
+	if err != nil {
+		errs.add(err)
+	}
+	if manifest.UIAccess, err = parseManifestBoolean(manifestJson, "uiAccess"); err != nil {
+		errs.add(err)
+	} else if manifest.UIAccess && manifest.RequestedExecutionLevel == "" {
+		manifest.RequestedExecutionLevel = "asInvoker"
+	}
+	if dpiAwareObj, ok := manifestJson["dpiAware"]; ok {
+		if dpiAware, ok := dpiAwareObj.(bool); ok {
+			manifest.DPIAware = fmt.Sprintf("%t", dpiAware)
+		} else if manifest.DPIAware, err = parseManifestString(manifestJson, "dpiAware",
+			dpiAwareNames...); err != nil {
+			errs.add(err)
+		}
+	}
+	if dpiAwarenessObj, ok := manifestJson["dpiAwareness"]; ok {
+		var names []interface{}
+		if name, ok := dpiAwarenessObj.(string); ok {
+			names = []interface{}{name}
+		} else if names, ok = dpiAwarenessObj.([]interface{}); !ok {
+			errs.add(fieldError("dpiAwareness", "must specify a string or a list of strings"))
+		}
+		values := make([]string, 0, len(names))
+		for _, nameObj := range names {
+			value, err := parseManifestString(map[string]interface{}{"dpiAwareness": nameObj}, "dpiAwareness",
+				dpiAwarenessNames...)
+			if err != nil {
+				errs.add(err)
+				break
+			}
+			values = append(values, value)
+		}
+		manifest.DPIAwareness = strings.Join(values, ", ")
+	}
+	if manifest.LongPathAware, err = parseManifestBoolean(manifestJson, "longPathAware"); err != nil {
+		errs.add(err)
+	}
+	if manifest.ActiveCodePage, err = parseManifestString(manifestJson, "activeCodePage",
+		activeCodePageNames...); err != nil {
+		errs.add(err)
+	}
+	if supportedOSObj, ok := manifestJson["supportedOS"]; ok {
+		if supportedOSArray, ok := supportedOSObj.([]interface{}); !ok {
+			errs.add(fieldError("supportedOS", "must specify a list of strings"))
+		} else {
+			seen := make(map[string]bool)
+			for i, osNameObj := range supportedOSArray {
+				osName, ok := osNameObj.(string)
+				if !ok {
+					errs.add(fieldError("supportedOS", "must specify a list of strings"))
+					break
+				}
+				id, ok := LookupSupportedOS(osName)
+				if !ok {
+					err := errors.New(fmt.Sprintf("invalid supported OS: %s", osName))
+					errs.add(atField("supportedOS", withPath(err, fmt.Sprintf("/%d", i))))
+					continue
+				}
+				if !seen[id] {
+					manifest.SupportedOS = append(manifest.SupportedOS, id)
+					seen[id] = true
+				}
+			}
+		}
+	}
+	if manifest.CommonControls, err = parseManifestBoolean(manifestJson, "commonControls"); err != nil {
+		errs.add(err)
+	}
+	if manifest.HeapType, err = parseManifestString(manifestJson, "heapType", heapTypeNames...); err != nil {
+		errs.add(err)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &manifest, nil
+}
+
+// defaultManifestResourceId returns the manifest resource ID that Windows looks for in the target file:
+// CREATEPROCESS_MANIFEST_RESOURCE_ID for executables and ISOLATIONAWARE_MANIFEST_RESOURCE_ID for DLLs.
+func defaultManifestResourceId(targetFileName string) (uint, error) {
+	if targetFileName == "" {
+		return uint(CREATEPROCESS_MANIFEST_RESOURCE_ID), nil
+	}
+	isDLL, err := IsDLL(targetFileName)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("could not determine the type of file '%s' (%s)", targetFileName, err))
+	}
+	if isDLL {
+		return uint(ISOLATIONAWARE_MANIFEST_RESOURCE_ID), nil
+	}
+	return uint(CREATEPROCESS_MANIFEST_RESOURCE_ID), nil
+}
+
+// resolveFileName resolves a file name given in a resource file against sourceDir, unless it is absolute.
+func resolveFileName(sourceDir string, fileName string) string {
+	if filepath.IsAbs(fileName) {
+		return fileName
+	}
+	return filepath.Join(sourceDir, fileName)
+}
+
+func parseManifestResource(manifestObj interface{}, sourceDir string, targetFileName string) (*Resource, error) {
+	var manifestRes *Resource
+	var resourceId uint
+	var err error
+	if manifestFileName, ok := manifestObj.(string); ok {
+		if manifestRes, err = loadManifestResource(resolveFileName(sourceDir, manifestFileName)); err != nil {
+			return nil, err
+		}
+	} else if manifestJson, ok := manifestObj.(map[string]interface{}); ok {
+		var errs ErrorList
+		settings := make(map[string]interface{})
+		for key, value := range manifestJson {
+			if key != "file" && key != "resourceId" {
+				settings[key] = value
+			}
+		}
+		if resourceIdObj, ok := manifestJson["resourceId"]; ok {
+			if id, err := parseInteger(resourceIdObj, "resourceId",
+				int64(CREATEPROCESS_MANIFEST_RESOURCE_ID),
+				int64(ISOLATIONAWARE_NOSTATICIMPORT_MANIFEST_RESOURCE_ID)); err != nil {
+				errs.add(err)
+			} else {
+				resourceId = uint(id)
+			}
+		}
+		if fileObj, ok := manifestJson["file"]; ok {
+			if manifestFileName, ok := fileObj.(string); !ok {
+				errs.add(fieldError("file", "must specify a file name"))
+			} else if len(settings) > 0 {
+				errs.add(fieldError("file", "cannot be combined with manifest settings"))
+			} else if manifestRes, err = loadManifestResource(resolveFileName(sourceDir, manifestFileName)); err != nil {
+				errs.add(atField("file", err))
+			}
+		} else if manifest, err := parseManifest(settings); err != nil {
+			errs.add(err)
+		} else {
+			manifestRes = &Resource{
+				Type: ResourceTypeManifest,
+				Data: EncodeManifest(manifest),
+			}
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+	} else {
+		return nil, errors.New("field manifest must specify a file name or an object")
+	}
+	if resourceId == 0 {
+		if resourceId, err = defaultManifestResourceId(targetFileName); err != nil {
+			return nil, err
+		}
+	}
+	manifestRes.Id = resourceId
+	return manifestRes, nil
+}
+
+// ParseResources compiles the resources described by the JSON data into a ResourceSet.  Relative file names are
+// resolved against sourceDir.  If targetFileName is not empty, it names the executable or DLL to which the resources
+// will be added, which determines the default manifest resource ID.  The resources are returned in a fixed order, as
+// sorted by SortResources.  Every problem found in the data is reported: the error is an ErrorList of PathErrors that
+// name the JSON pointer of the offending value.  Numbers may be given as int64, or as the float64 and json.Number
+// values that encoding/json produces, as long as integer fields are given integral values.
+func ParseResources(
+	jsonData map[string]interface{},
+	sourceDir string,
+	targetFileName string) (*ResourceSet, error) {
+	jsonData = normalizeValue(jsonData).(map[string]interface{})
+	var errs ErrorList
+	language := LanguageNeutral
+	if languageObj, ok := jsonData["language"]; ok {
+		if languageName, ok := languageObj.(string); ok {
+			if languageId, err := LookupLanguage(languageName); err != nil {
+				errs.add(atField("language", err))
+			} else {
+				language = languageId
+			}
+		} else {
+			errs.add(fieldError("language", "must specify a string"))
+		}
+	}
+	if schemaVersionObj, ok := jsonData["$schemaVersion"]; ok {
+		if schemaVersion, ok := schemaVersionObj.(int64); ok && schemaVersion > ResourceSchemaVersion {
+			errs.add(fieldError("$schemaVersion", "specifies format version %d, but gorc only supports versions up "+
+				"to %d", schemaVersion, ResourceSchemaVersion))
+		} else if _, err := parseInteger(schemaVersionObj, "$schemaVersion", 1, ResourceSchemaVersion); err != nil {
+			errs.add(err)
+		}
+	}
+	resources := make([]*Resource, 0)
+	for key, value := range jsonData {
+		var sectionResources []*Resource
+		var err error
+		switch key {
+		case "version":
+			if versionJson, ok := value.(map[string]interface{}); ok {
+				var versionRes *Resource
+				if versionRes, err = parseVersionResource(versionJson, language, targetFileName); err == nil {
+					sectionResources = []*Resource{versionRes}
+				}
+			} else {
+				err = errors.New("field version must specify an object")
+			}
+		case "messageTable":
+			if messageJson, ok := value.([]interface{}); ok {
+				var messageRes *Resource
+				if messageRes, err = parseMessageTableResource(messageJson); err == nil {
+					sectionResources = []*Resource{messageRes}
+				}
+			} else {
+				err = errors.New("field messageTable must specify a list of objects")
+			}
+		case "manifest":
+			var manifestRes *Resource
+			if manifestRes, err = parseManifestResource(value, sourceDir, targetFileName); err == nil {
+				sectionResources = []*Resource{manifestRes}
+			}
+		case "dialogs":
+			if dialogsJson, ok := value.([]interface{}); ok {
+				sectionResources, err = parseDialogResources(dialogsJson)
+			} else {
+				err = errors.New("field dialogs must specify a list of objects")
+			}
+		case "menus":
+			if menusJson, ok := value.([]interface{}); ok {
+				sectionResources, err = parseMenuResources(menusJson)
+			} else {
+				err = errors.New("field menus must specify a list of objects")
+			}
+		case "accelerators":
+			if tablesJson, ok := value.([]interface{}); ok {
+				sectionResources, err = parseAcceleratorResources(tablesJson)
+			} else {
+				err = errors.New("field accelerators must specify a list of objects")
+			}
+		case "fonts":
+			if fontsJson, ok := value.([]interface{}); ok {
+				sectionResources, err = loadFontResources(fontsJson, sourceDir)
+			} else {
+				err = errors.New("field fonts must specify a list of objects")
+			}
+		case "stringTable":
+			if stringTableJson, ok := value.([]interface{}); ok {
+				sectionResources, err = parseStringTableResources(stringTableJson)
+			} else {
+				err = errors.New("field stringTable must specify a list of objects")
+			}
+		case "rcdata":
+			if rcdataJson, ok := value.([]interface{}); ok {
+				sectionResources, err = parseRCDataResources(rcdataJson, sourceDir)
+			} else {
+				err = errors.New("field rcdata must specify a list of objects")
+			}
+		case "language", "$schemaVersion":
+			// handled above
+		case "$schema":
+			if _, ok := value.(string); !ok {
+				err = errors.New("field $schema must specify a string")
+			}
+		default:
+			err = errors.New(fmt.Sprintf("invalid resource type %s", key))
+		}
+		if err != nil {
+			errs.add(atField(key, err))
+		} else {
+			resources = append(resources, sectionResources...)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	SortResources(resources)
+	return &ResourceSet{Language: language, Resources: resources}, nil
+}
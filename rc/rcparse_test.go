@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package rc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// TestParseResourcesFromEncodingJSON checks that data decoded by encoding/json, which gives numbers as float64 or
+// json.Number, compiles to the same resources as data decoded by DecodeJSONC, which gives integers as int64.
+func TestParseResourcesFromEncodingJSON(t *testing.T) {
+	data := []byte(`{
+		"messageTable": [{"id": 1, "severity": "Error", "messageText": "Hello, %1!"}],
+		"version": {"fileVersion": "1.2.3.4", "fileFlags": 3, "fileDateMS": 1, "fileDateLS": 2},
+		"menus": [{"id": 200, "items": [{"text": "E&xit", "id": 40003}]}],
+		"accelerators": [{"id": 1, "entries": [{"key": "Ctrl+S", "command": 40001}]}]
+	}`)
+	jsoncData, _, err := DecodeJSONC(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := ParseResources(jsoncData, "", "")
+	if err != nil {
+		t.Fatalf("failed to parse resources decoded by DecodeJSONC: %s", err)
+	}
+
+	var floatData map[string]interface{}
+	if err := json.Unmarshal(data, &floatData); err != nil {
+		t.Fatal(err)
+	}
+	var numberData map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&numberData); err != nil {
+		t.Fatal(err)
+	}
+	for name, jsonData := range map[string]map[string]interface{}{"float64": floatData, "json.Number": numberData} {
+		set, err := ParseResources(jsonData, "", "")
+		if err != nil {
+			t.Errorf("failed to parse resources with %s numbers: %s", name, err)
+			continue
+		}
+		if len(set.Resources) != len(expected.Resources) {
+			t.Errorf("parsed %d resources with %s numbers, expected %d", len(set.Resources), name,
+				len(expected.Resources))
+			continue
+		}
+		for i, res := range set.Resources {
+			if !bytes.Equal(res.Data, expected.Resources[i].Data) {
+				t.Errorf("resource %d differs with %s numbers", i, name)
+			}
+		}
+	}
+	if _, ok := floatData["version"].(map[string]interface{})["fileFlags"].(float64); !ok {
+		t.Error("ParseResources changed the data passed to it")
+	}
+
+	floatData["menus"].([]interface{})[0].(map[string]interface{})["id"] = 200.5
+	if _, err := ParseResources(floatData, "", ""); err == nil {
+		t.Error("a fractional resource ID was accepted")
+	}
+}
+
+func TestParseFileDate(t *testing.T) {
+	for _, test := range []struct {
+		Date string
+		MS   uint32
+		LS   uint32
+	}{
+		{"1601-01-01T00:00:00Z", 0, 0},
+		{"1970-01-01T00:00:00Z", 0x019DB1DE, 0xD53E8000},
+		{"2014-06-01T00:00:00.0000001Z", 0x01CF7D2C, 0x6E530001},
+		{"9999-12-31T23:59:59Z", 0x24C85A5E, 0xD127A980},
+	} {
+		ms, ls, err := parseFileDate(test.Date)
+		if err != nil {
+			t.Errorf("%s: %s", test.Date, err)
+		} else if ms != test.MS || ls != test.LS {
+			t.Errorf("%s: got 0x%08X%08X, expected 0x%08X%08X", test.Date, ms, ls, test.MS, test.LS)
+		}
+	}
+	if _, _, err := parseFileDate("1600-12-31T23:59:59Z"); err == nil {
+		t.Errorf("a date before 1601 was accepted")
+	}
+}
+
+func TestParseStringTableAndRCData(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"config.bin": "\x00\x01\x02"})
+	jsonData, _, err := DecodeJSONC([]byte(`{
+		"stringTable": [{"id": 1, "text": "One"}, {"id": 100, "text": "Hundred"}],
+		"rcdata": [{"id": 5, "file": "config.bin"}, {"name": "Greeting", "text": "hello"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := ParseResources(jsonData, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string][]byte)
+	for _, res := range set.Resources {
+		found[fmt.Sprintf("%d/%d/%s", res.Type, res.Id, res.Name)] = res.Data
+	}
+	for key, data := range map[string]string{
+		fmt.Sprintf("%d/5/", ResourceTypeRCData):         "\x00\x01\x02",
+		fmt.Sprintf("%d/0/Greeting", ResourceTypeRCData): "hello",
+	} {
+		if string(found[key]) != data {
+			t.Errorf("resource %s is %q, expected %q", key, found[key], data)
+		}
+	}
+	for _, blockId := range []uint{1, 7} {
+		if _, ok := found[fmt.Sprintf("%d/%d/", ResourceTypeString, blockId)]; !ok {
+			t.Errorf("string table block %d is missing", blockId)
+		}
+	}
+
+	for _, test := range []struct {
+		Json  string
+		Paths []string
+	}{
+		{`{"stringTable": [{"id": 1, "text": "a"}, {"id": 1, "text": "b"}]}`, []string{"/stringTable/1/id"}},
+		{`{"stringTable": [{"id": 65536, "text": "a"}, {"text": ""}]}`,
+			[]string{"/stringTable/0/id", "/stringTable/1/id", "/stringTable/1/text"}},
+		{`{"rcdata": [{"id": 1, "name": "A", "text": "a"}, {"text": "b"}]}`, []string{"/rcdata/0/name", "/rcdata/1/id"}},
+		{`{"rcdata": [{"id": 1, "file": "x", "text": "a"}, {"id": 2}]}`, []string{"/rcdata/0/text", "/rcdata/1/file"}},
+		{`{"rcdata": [{"id": 1, "file": "missing.bin"}]}`, []string{"/rcdata/0/file"}},
+		{`{"rcdata": [{"name": "a", "text": "a"}, {"name": "A", "text": "b"}]}`, []string{"/rcdata/1"}},
+	} {
+		jsonData, _, err := DecodeJSONC([]byte(test.Json))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ParseResources(jsonData, dir, "")
+		if err == nil {
+			t.Errorf("%s: no error", test.Json)
+		} else if paths := errorPaths(err); !reflect.DeepEqual(paths, test.Paths) {
+			t.Errorf("%s: errors at %q, expected %q (%s)", test.Json, paths, test.Paths, err)
+		}
+	}
+}
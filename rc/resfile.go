@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Memory flags recorded for each resource in a .res file: MOVEABLE, PURE and DISCARDABLE, as rc.exe writes them.
+const resMemoryFlags = 0x1030
+
+// resourceTypeOrdinal returns the numeric value of a predefined resource type.  Resource types given by name cannot
+// be written by the .res and .syso writers.
+func resourceTypeOrdinal(res *Resource) (uint16, error) {
+	if res.Type == 0 || res.Type > 0xFFFF {
+		return 0, errors.New("resources with named types are not supported")
+	}
+	return uint16(res.Type), nil
+}
+
+func writeRESNameOrOrdinal(buf *bytes.Buffer, name string, ordinal uint16) {
+	if name != "" {
+		writeUTF16String(buf, name)
+	} else {
+		binary.Write(buf, binary.LittleEndian, uint16(0xFFFF))
+		binary.Write(buf, binary.LittleEndian, ordinal)
+	}
+}
+
+func writeRESEntry(buf *bytes.Buffer, typeOrdinal uint16, name string, id uint16, memoryFlags uint16, language uint16,
+	data []byte) {
+	var header bytes.Buffer
+	writeRESNameOrOrdinal(&header, "", typeOrdinal)
+	writeRESNameOrOrdinal(&header, name, id)
+	alignBuffer(&header, 4)
+	binary.Write(&header, binary.LittleEndian, uint32(0)) // DataVersion
+	binary.Write(&header, binary.LittleEndian, memoryFlags)
+	binary.Write(&header, binary.LittleEndian, language)
+	binary.Write(&header, binary.LittleEndian, uint32(0)) // Version
+	binary.Write(&header, binary.LittleEndian, uint32(0)) // Characteristics
+	binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	binary.Write(buf, binary.LittleEndian, uint32(8+header.Len()))
+	buf.Write(header.Bytes())
+	buf.Write(data)
+	alignBuffer(buf, 4)
+}
+
+// WriteRES writes the resources in the format of a compiled resource (.res) file, which linkers such as link.exe and
+// windres accept.
+func (set *ResourceSet) WriteRES(w io.Writer) error {
+	var buf bytes.Buffer
+	// a .res file starts with an empty entry that identifies it as a 32-bit resource file
+	writeRESEntry(&buf, 0, "", 0, 0, 0, nil)
+	for _, res := range set.Resources {
+		typeOrdinal, err := resourceTypeOrdinal(res)
+		if err != nil {
+			return err
+		}
+		if res.Name == "" && res.Id > 0xFFFF {
+			return errors.New(fmt.Sprintf("invalid resource ID %d", res.Id))
+		}
+		writeRESEntry(&buf, typeOrdinal, res.Name, uint16(res.Id), resMemoryFlags, uint16(set.Language), res.Data)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package rc compiles Win32 resources from JSON, YAML or TOML descriptions and writes them into executables, .res
+// files or .syso objects for the Go linker.  A ResourceSet may be read from a resource file with LoadResourceFile or
+// built up from the Encode functions for each resource type.
+package rc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// ResourceSet is a list of resources in one language, kept in the order of a resource directory.
+type ResourceSet struct {
+	Language  Language
+	Resources []*Resource
+}
+
+// NewResourceSet returns an empty ResourceSet for the given language.
+func NewResourceSet(language Language) *ResourceSet {
+	return &ResourceSet{Language: language}
+}
+
+func (set *ResourceSet) add(res *Resource) {
+	for i, other := range set.Resources {
+		if other.Type == res.Type && other.Id == res.Id && other.Name == res.Name {
+			set.Resources[i] = res
+			return
+		}
+	}
+	set.Resources = append(set.Resources, res)
+	SortResources(set.Resources)
+}
+
+// Add adds a resource identified by ID, replacing any resource of the same type with the same ID.
+func (set *ResourceSet) Add(resourceType ResourceType, id uint, data []byte) {
+	set.add(&Resource{Type: resourceType, Id: id, Data: data})
+}
+
+// AddNamed adds a resource identified by name, replacing any resource of the same type with the same name.
+func (set *ResourceSet) AddNamed(resourceType ResourceType, name string, data []byte) {
+	set.add(&Resource{Type: resourceType, Name: name, Data: data})
+}
+
+// LoadOptions controls how LoadResourceFile reads a resource file.
+type LoadOptions struct {
+	// Defines holds the values of the template variables given by .Define.
+	Defines map[string]string
+
+	// SourceDir is the directory against which relative file names in the resource file are resolved.  If it is
+	// empty, the directory that contains the resource file is used.
+	SourceDir string
+
+	// TargetFileName names the executable or DLL to which the resources will be added, if there is one.
+	TargetFileName string
+
+	// Overrides set individual fields of the merged resource data before templates are expanded and the resources
+	// are compiled.  See ApplyOverrides.
+	Overrides []Override
+
+	// Verbose, if not nil, receives a line describing each override as it is applied.
+	Verbose io.Writer
+}
+
+// LoadResourceFile reads, expands and compiles a resource file in any of the formats accepted by DecodeResourceFile.
+// The source map can be used to find the positions of the values named by the PathErrors in the returned error.
+func LoadResourceFile(fileName string, options *LoadOptions) (*ResourceSet, SourceMap, error) {
+	return LoadResourceFiles([]string{fileName}, options)
+}
+
+// LoadResourceFiles is like LoadResourceFile, but reads one or more resource files and merges them in order with
+// DecodeResourceFiles.  Relative file names are resolved against the directory that contains the last file, unless
+// SourceDir is set.
+func LoadResourceFiles(fileNames []string, options *LoadOptions) (*ResourceSet, SourceMap, error) {
+	if options == nil {
+		options = &LoadOptions{}
+	}
+	jsonData, sourceMap, err := DecodeResourceFiles(fileNames)
+	if err != nil {
+		return nil, sourceMap, err
+	}
+	values, err := ApplyOverrides(jsonData, sourceMap, options.Overrides)
+	if err != nil {
+		return nil, sourceMap, err
+	}
+	if options.Verbose != nil {
+		for i, override := range options.Overrides {
+			value, _ := json.Marshal(values[i])
+			fmt.Fprintf(options.Verbose, "set %s = %s\n", override.Path, value)
+		}
+	}
+	sourceDir := options.SourceDir
+	if sourceDir == "" {
+		if sourceDir, err = filepath.Abs(filepath.Dir(fileNames[len(fileNames)-1])); err != nil {
+			return nil, sourceMap, err
+		}
+	}
+	templateData, err := NewTemplateData(options.Defines, sourceDir)
+	if err != nil {
+		return nil, sourceMap, err
+	}
+	if jsonData, err = ExpandTemplates(jsonData, templateData); err != nil {
+		return nil, sourceMap, err
+	}
+	set, err := ParseResources(jsonData, sourceDir, options.TargetFileName)
+	return set, sourceMap, err
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package rc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const testResourceFile = `{
+	"language": "en-US",
+	"messageTable": [
+		{"id": 1, "severity": "Error", "messageText": "Hello, %1!"},
+		{"id": 2, "severity": "Warning", "messageText": "Goodbye, %1!"}
+	],
+	"version": {
+		"fileVersion": "1.2.3.4",
+		"productVersion": "1.2.0.0",
+		"fileDate": "2014-06-01T00:00:00Z",
+		"stringFileInfo": {
+			"companyName": "MongoDB",
+			"fileDescription": "Hello",
+			"originalFilename": "hello.exe",
+			"productName": "Hello"
+		}
+	},
+	"manifest": {"requestedExecutionLevel": "asInvoker", "commonControls": true},
+	"menus": [
+		{"id": 200, "items": [{"text": "Tray", "items": [{"text": "E&xit", "id": 40003}]}]},
+		{"id": 100, "format": "menu", "items": [{"text": "&Open", "id": 40001}]}
+	],
+	"accelerators": [
+		{"id": 1, "entries": [{"key": "Ctrl+S", "command": 40001}, {"key": "Alt+F4", "command": 40003}]}
+	]
+}`
+
+// writeTestResources loads the test resource file and returns it written as a .res file and as a .syso object.
+func writeTestResources(t *testing.T, fileName string) ([]byte, []byte) {
+	set, _, err := LoadResourceFile(fileName, nil)
+	if err != nil {
+		t.Fatalf("failed to load resource file: %s", err)
+	}
+	var res, syso bytes.Buffer
+	if err := set.WriteRES(&res); err != nil {
+		t.Fatalf("failed to write .res file: %s", err)
+	}
+	if err := set.WriteSyso(&syso, "amd64"); err != nil {
+		t.Fatalf("failed to write .syso file: %s", err)
+	}
+	return res.Bytes(), syso.Bytes()
+}
+
+func TestWriteIsReproducible(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "hello_resources.json")
+	if err := ioutil.WriteFile(fileName, []byte(testResourceFile), 0666); err != nil {
+		t.Fatal(err)
+	}
+	firstRES, firstSyso := writeTestResources(t, fileName)
+	// the fields of the resource file are read from a map, so several runs are needed to vary their order
+	for i := 0; i < 10; i++ {
+		res, syso := writeTestResources(t, fileName)
+		if !bytes.Equal(res, firstRES) {
+			t.Fatalf("run %d wrote a different .res file", i+2)
+		}
+		if !bytes.Equal(syso, firstSyso) {
+			t.Fatalf("run %d wrote a different .syso file", i+2)
+		}
+	}
+}
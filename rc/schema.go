@@ -0,0 +1,358 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ResourceSchemaVersion is the version of the resource file format understood by this version of gorc.  It is
+// incremented whenever a change to the format would alter the meaning of existing files, and resource files may
+// declare the version they were written for in the $schemaVersion field.
+const ResourceSchemaVersion = 1
+
+type schema map[string]interface{}
+
+func stringSchema(description string) schema {
+	return schema{"type": "string", "description": description}
+}
+
+func booleanSchema(description string) schema {
+	return schema{"type": "boolean", "description": description}
+}
+
+func integerSchema(min int64, max int64) schema {
+	return schema{"type": "integer", "minimum": min, "maximum": max}
+}
+
+func enumSchema(names []string) schema {
+	return schema{"type": "string", "enum": names}
+}
+
+func listSchema(items schema) schema {
+	return schema{"type": "array", "items": items}
+}
+
+// objectSchema describes an object with the given properties.  If closed is true, no other properties are allowed,
+// which should only be the case where ParseResources rejects unknown fields.
+func objectSchema(properties map[string]schema, closed bool, required ...string) schema {
+	result := schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+	if closed {
+		result["additionalProperties"] = false
+	}
+	return result
+}
+
+func namedValueNames(names []namedValue) []string {
+	result := make([]string, 0, len(names))
+	for _, named := range names {
+		result = append(result, named.Name)
+	}
+	return result
+}
+
+func sortedKeys(values map[string]uint32) []string {
+	result := make([]string, 0, len(values))
+	for key := range values {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// flagsSchema describes a field that takes either a list of constant names, which are ORed together, or the
+// numeric value.
+func flagsSchema(names []string, description string) schema {
+	return schema{
+		"description": description,
+		"oneOf": []schema{
+			listSchema(enumSchema(names)),
+			integerSchema(0, math.MaxUint32),
+		},
+	}
+}
+
+// constantSchema describes a field that takes either the name of a constant or its numeric value.
+func constantSchema(names []string, description string) schema {
+	return schema{
+		"description": description,
+		"oneOf": []schema{
+			enumSchema(names),
+			integerSchema(0, math.MaxUint32),
+		},
+	}
+}
+
+func nameOrOrdinalSchema(description string) schema {
+	return schema{
+		"description": description,
+		"oneOf": []schema{
+			{"type": "string"},
+			integerSchema(1, 0xFFFF),
+		},
+	}
+}
+
+func versionSchema() schema {
+	stringFileInfoProperties := make(map[string]schema)
+	for _, field := range stringFileInfoFields {
+		stringFileInfoProperties[field.JsonName] = stringSchema(fmt.Sprintf("the %s string", field.WinName))
+	}
+	stringFileInfoProperties["custom"] = schema{
+		"type":                 "object",
+		"description":          "additional strings, keyed by name",
+		"additionalProperties": schema{"type": "string"},
+	}
+	subtypeNames := make([]string, 0, len(fileSubtypeNames))
+	for _, subtype := range fileSubtypeNames {
+		subtypeNames = append(subtypeNames, subtype.Name)
+	}
+	fileFlags := namedValueNames(fileFlagNames)
+	return objectSchema(map[string]schema{
+		"strucVersion":   integerSchema(0, math.MaxUint32),
+		"semverRule":     stringSchema("how a semantic version maps onto the four parts of a file version"),
+		"fileVersion":    stringSchema("a four-part version number or a semantic version"),
+		"productVersion": stringSchema("a four-part version number or a semantic version"),
+		"fileFlagsMask":  flagsSchema(fileFlags, "the flags that are valid in fileFlags"),
+		"fileFlags":      flagsSchema(fileFlags, "the VS_FF_ flags of the file"),
+		"fileOS":         constantSchema(namedValueNames(fileOSNames), "the operating system of the file"),
+		"fileType":       constantSchema(namedValueNames(fileTypeNames), "the type of the file"),
+		"fileSubtype":    constantSchema(subtypeNames, "the subtype of a driver, font or VxD file"),
+		"fileDate":       schema{"type": "string", "format": "date-time"},
+		"fileDateMS":     integerSchema(0, math.MaxUint32),
+		"fileDateLS":     integerSchema(0, math.MaxUint32),
+		"stringFileInfo": objectSchema(stringFileInfoProperties, false),
+		"buildInfo": schema{
+			"description": "fill fields from the Go build information of the target executable",
+			"oneOf": []schema{
+				{"type": "boolean"},
+				objectSchema(map[string]schema{
+					"productVersion": booleanSchema("use the main module version as the product version"),
+					"revisionKey":    stringSchema("the custom string that receives the VCS revision"),
+					"privateBuild":   booleanSchema("describe modified working trees in PrivateBuild"),
+				}, true),
+			},
+		},
+	}, false)
+}
+
+func messageTableSchema() schema {
+	return listSchema(objectSchema(map[string]schema{
+		"id":          integerSchema(0, math.MaxUint32),
+		"severity":    enumSchema(namedValueNames(messageSeverityNames)),
+		"messageText": stringSchema("the text of the message"),
+	}, false, "id", "severity", "messageText"))
+}
+
+func manifestSchema() schema {
+	settings := map[string]schema{
+		"assemblyIdentity": objectSchema(map[string]schema{
+			"name":                  stringSchema("the name of the assembly"),
+			"version":               stringSchema("a four-part version number"),
+			"processorArchitecture": enumSchema(processorArchitectureNames),
+		}, false, "name"),
+		"requestedExecutionLevel": enumSchema(executionLevelNames),
+		"uiAccess":                booleanSchema("whether the application bypasses UI protection levels"),
+		"dpiAware": schema{
+			"oneOf": []schema{{"type": "boolean"}, enumSchema(dpiAwareNames)},
+		},
+		"dpiAwareness": schema{
+			"oneOf": []schema{enumSchema(dpiAwarenessNames), listSchema(enumSchema(dpiAwarenessNames))},
+		},
+		"longPathAware":  booleanSchema("whether the application supports paths longer than MAX_PATH"),
+		"activeCodePage": enumSchema(activeCodePageNames),
+		"supportedOS":    listSchema(stringSchema("a Windows version such as \"Windows 10\"")),
+		"commonControls": booleanSchema("whether to use version 6 of the common controls"),
+		"heapType":       enumSchema(heapTypeNames),
+	}
+	properties := make(map[string]schema)
+	for _, settingName := range manifestSettingNames {
+		if setting, ok := settings[settingName]; ok {
+			properties[settingName] = setting
+		} else {
+			panic(fmt.Sprintf("no schema for manifest setting %s", settingName))
+		}
+	}
+	properties["file"] = stringSchema("a manifest file to embed instead of the settings")
+	properties["resourceId"] = integerSchema(int64(CREATEPROCESS_MANIFEST_RESOURCE_ID),
+		int64(ISOLATIONAWARE_NOSTATICIMPORT_MANIFEST_RESOURCE_ID))
+	return schema{
+		"oneOf": []schema{
+			stringSchema("a manifest file to embed"),
+			objectSchema(properties, true),
+		},
+	}
+}
+
+func rectSchema() schema {
+	item := integerSchema(math.MinInt16, math.MaxInt16)
+	return schema{"type": "array", "items": item, "minItems": 4, "maxItems": 4}
+}
+
+func dialogsSchema() schema {
+	windowStyleNames := sortedKeys(windowStyles)
+	windowExStyleNames := sortedKeys(windowExStyles)
+	classNames := make([]string, 0, len(dialogClassOrdinals))
+	for className := range dialogClassOrdinals {
+		classNames = append(classNames, className)
+	}
+	sort.Strings(classNames)
+	control := objectSchema(map[string]schema{
+		"class": schema{
+			"description": "a window class name or ordinal",
+			"anyOf":       []schema{enumSchema(classNames), {"type": "string"}, integerSchema(1, 0xFFFF)},
+		},
+		"text":    nameOrOrdinalSchema("the text of the control or the ordinal of a resource"),
+		"id":      integerSchema(-1, math.MaxUint32),
+		"rect":    rectSchema(),
+		"style":   flagsSchema(windowStyleNames, "window styles added to WS_CHILD and WS_VISIBLE"),
+		"exStyle": flagsSchema(windowExStyleNames, "extended window styles"),
+		"helpId":  integerSchema(0, math.MaxUint32),
+	}, false, "class", "rect")
+	return listSchema(objectSchema(map[string]schema{
+		"id":      integerSchema(1, 0xFFFF),
+		"style":   flagsSchema(windowStyleNames, "window styles"),
+		"exStyle": flagsSchema(windowExStyleNames, "extended window styles"),
+		"rect":    rectSchema(),
+		"helpId":  integerSchema(0, math.MaxUint32),
+		"caption": stringSchema("the title of the dialog"),
+		"menu":    nameOrOrdinalSchema("the name or ID of a menu resource"),
+		"class":   nameOrOrdinalSchema("the window class of the dialog"),
+		"font": objectSchema(map[string]schema{
+			"typeface":  stringSchema("the name of the font"),
+			"pointSize": integerSchema(1, 0xFFFF),
+			"weight":    integerSchema(0, 1000),
+			"italic":    booleanSchema("whether the font is italic"),
+			"charset":   integerSchema(0, 0xFF),
+		}, false, "typeface"),
+		"controls": listSchema(control),
+	}, false, "id", "rect"))
+}
+
+func menuItemSchema() schema {
+	properties := map[string]schema{
+		"text":   stringSchema("the text of the item"),
+		"id":     integerSchema(0, math.MaxUint32),
+		"helpId": integerSchema(0, math.MaxUint32),
+		"items":  listSchema(schema{"$ref": "#/definitions/menuItem"}),
+	}
+	for _, flag := range menuItemFlags {
+		if flag.MenuExOnly {
+			properties[flag.JsonName] = booleanSchema("only supported in MENUEX format")
+		} else {
+			properties[flag.JsonName] = schema{"type": "boolean"}
+		}
+	}
+	return objectSchema(properties, false)
+}
+
+func menusSchema() schema {
+	return listSchema(objectSchema(map[string]schema{
+		"id":     integerSchema(1, 0xFFFF),
+		"format": enumSchema([]string{"menuex", "menu"}),
+		"helpId": integerSchema(0, math.MaxUint32),
+		"items":  listSchema(schema{"$ref": "#/definitions/menuItem"}),
+	}, false, "id", "items"))
+}
+
+func acceleratorsSchema() schema {
+	entry := objectSchema(map[string]schema{
+		"key":      stringSchema("a key chord such as \"Ctrl+Shift+S\""),
+		"command":  integerSchema(0, 0xFFFF),
+		"noInvert": booleanSchema("whether the menu bar is not highlighted"),
+	}, false, "key", "command")
+	return listSchema(objectSchema(map[string]schema{
+		"id":      integerSchema(1, 0xFFFF),
+		"entries": schema{"type": "array", "items": entry, "minItems": 1},
+	}, false, "id", "entries"))
+}
+
+func fontsSchema() schema {
+	return listSchema(objectSchema(map[string]schema{
+		"id":   integerSchema(1, 0xFFFF),
+		"file": stringSchema("a .ttf, .otf, .ttc, .fnt or .fon file"),
+	}, false, "id", "file"))
+}
+
+func stringTableSchema() schema {
+	return listSchema(objectSchema(map[string]schema{
+		"id":   integerSchema(0, 0xFFFF),
+		"text": stringSchema("the text of the string"),
+	}, false, "id", "text"))
+}
+
+func rcdataSchema() schema {
+	rcdata := objectSchema(map[string]schema{
+		"id":   integerSchema(1, 0xFFFF),
+		"name": stringSchema("the name of the resource, instead of an ID"),
+		"file": stringSchema("a file whose contents are embedded"),
+		"text": stringSchema("text to embed as UTF-8, instead of a file"),
+	}, false)
+	rcdata["allOf"] = []schema{
+		{"oneOf": []schema{{"required": []string{"id"}}, {"required": []string{"name"}}}},
+		{"oneOf": []schema{{"required": []string{"file"}}, {"required": []string{"text"}}}},
+	}
+	return listSchema(rcdata)
+}
+
+// ResourceSchema returns a JSON Schema (draft 7) for resource files.  The names it allows are taken from the same
+// tables that ParseResources uses, and TestSchemaMatchesParser checks that both know the same fields.
+func ResourceSchema() map[string]interface{} {
+	return schema{
+		"$schema":  "http://json-schema.org/draft-07/schema#",
+		"title":    "gorc resource file",
+		"$comment": fmt.Sprintf("resource file format version %d", ResourceSchemaVersion),
+		"type":     "object",
+		"properties": map[string]schema{
+			"$schema":        stringSchema("the location of this schema, for editors"),
+			"$schemaVersion": integerSchema(1, ResourceSchemaVersion),
+			"extends": schema{
+				"description": "resource files to merge beneath this one",
+				"oneOf":       []schema{{"type": "string"}, listSchema(schema{"type": "string"})},
+			},
+			"language":     stringSchema("a locale name such as \"en-US\" or a language ID such as \"0x0409\""),
+			"version":      versionSchema(),
+			"messageTable": messageTableSchema(),
+			"manifest":     manifestSchema(),
+			"dialogs":      dialogsSchema(),
+			"menus":        menusSchema(),
+			"accelerators": acceleratorsSchema(),
+			"fonts":        fontsSchema(),
+			"stringTable":  stringTableSchema(),
+			"rcdata":       rcdataSchema(),
+		},
+		"additionalProperties": false,
+		"definitions": map[string]schema{
+			"menuItem": menuItemSchema(),
+		},
+	}
+}
+
+// EncodeResourceSchema returns ResourceSchema as indented JSON.
+func EncodeResourceSchema() []byte {
+	data, err := json.MarshalIndent(ResourceSchema(), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return append(data, '\n')
+}
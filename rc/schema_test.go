@@ -0,0 +1,187 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func isJSONObjectName(name string) bool {
+	return name == "jsonData" || strings.HasSuffix(name, "Json")
+}
+
+// parsedFields finds the fields that the parsing code reads, by looking for string constants used as keys of the
+// decoded objects, whose variables are named jsonData or ...Json, and as cases of switch statements over the keys of
+// an object.  The fields are keyed by the function and variable that read them, such as "parseMenuResource.menuJson".
+func parsedFields(t *testing.T, fileNames ...string) map[string]map[string]bool {
+	fields := make(map[string]map[string]bool)
+	add := func(reader string, lit ast.Expr) {
+		if lit, ok := lit.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			name, _ := strconv.Unquote(lit.Value)
+			if fields[reader] == nil {
+				fields[reader] = make(map[string]bool)
+			}
+			fields[reader][name] = true
+		}
+	}
+	fileSet := token.NewFileSet()
+	for _, fileName := range fileNames {
+		file, err := parser.ParseFile(fileSet, fileName, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Body == nil {
+				continue
+			}
+			ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.IndexExpr:
+					if ident, ok := node.X.(*ast.Ident); ok && isJSONObjectName(ident.Name) {
+						add(funcDecl.Name.Name+"."+ident.Name, node.Index)
+					}
+				case *ast.SwitchStmt:
+					if ident, ok := node.Tag.(*ast.Ident); ok && ident.Name == "key" {
+						for _, stmt := range node.Body.List {
+							for _, expr := range stmt.(*ast.CaseClause).List {
+								add(funcDecl.Name.Name+".key", expr)
+							}
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+	return fields
+}
+
+// schemaProperties returns the properties of the object that the path leads to, where "[]" steps into the items of
+// a list and alternatives are resolved to the one that is an object or a list.
+func schemaProperties(t *testing.T, root schema, path string) map[string]schema {
+	resolve := func(s schema) schema {
+		if ref, ok := s["$ref"].(string); ok {
+			s = root["definitions"].(map[string]schema)[strings.TrimPrefix(ref, "#/definitions/")]
+		}
+		if options, ok := s["oneOf"].([]schema); ok {
+			for _, option := range options {
+				if option["type"] == "object" || option["type"] == "array" {
+					return option
+				}
+			}
+		}
+		return s
+	}
+	s := root
+	for _, step := range strings.Split(path, "/")[1:] {
+		if step == "[]" {
+			s = resolve(s["items"].(schema))
+		} else {
+			property, ok := s["properties"].(map[string]schema)[step]
+			if !ok {
+				t.Fatalf("schema has no property %s in %s", step, path)
+			}
+			s = resolve(property)
+		}
+	}
+	properties, ok := s["properties"].(map[string]schema)
+	if !ok {
+		t.Fatalf("schema at %s is not an object", path)
+	}
+	return properties
+}
+
+func TestSchemaMatchesParser(t *testing.T) {
+	var stringFileInfoNames, menuItemFlagNames []string
+	for _, field := range stringFileInfoFields {
+		stringFileInfoNames = append(stringFileInfoNames, field.JsonName)
+	}
+	for _, flag := range menuItemFlags {
+		menuItemFlagNames = append(menuItemFlagNames, flag.JsonName)
+	}
+	// the schema path of the object read by each reader, and the fields that it reads from tables rather than by name
+	objects := []struct {
+		Path        string
+		Readers     []string
+		TableFields []string
+	}{
+		{"", []string{"ParseResources.jsonData", "ParseResources.key"}, nil},
+		{"/version", []string{"parseVersionResource.versionJson", "parseStringFileInfo.versionJson",
+			"applyGoBuildInfo.versionJson"}, versionFieldNames},
+		{"/version/stringFileInfo", []string{"parseStringFileInfo.stringFileInfoJson",
+			"applyGoBuildInfo.stringFileInfoJson"}, stringFileInfoNames},
+		{"/version/buildInfo", []string{"parseBuildInfoOptions.key"}, nil},
+		{"/messageTable/[]", []string{"parseMessageTableResource.messageJson"}, nil},
+		{"/manifest", []string{"parseManifest.manifestJson", "parseManifestResource.manifestJson"},
+			manifestSettingNames},
+		{"/dialogs/[]", []string{"parseDialogResource.dialogJson"}, nil},
+		{"/dialogs/[]/font", []string{"parseDialogFont.fontJson"}, nil},
+		{"/dialogs/[]/controls/[]", []string{"parseDialogControl.controlJson"}, nil},
+		{"/menus/[]", []string{"parseMenuResource.menuJson"}, nil},
+		{"/menus/[]/items/[]", []string{"parseMenuItems.itemJson"}, menuItemFlagNames},
+		{"/accelerators/[]", []string{"parseAcceleratorResource.tableJson"}, nil},
+		{"/accelerators/[]/entries/[]", []string{"parseAcceleratorResource.entryJson"}, nil},
+		{"/fonts/[]", []string{"loadFont.fontJson"}, nil},
+		{"/stringTable/[]", []string{"parseStringTableResources.stringJson"}, nil},
+		{"/rcdata/[]", []string{"parseRCDataResources.rcdataJson"}, nil},
+	}
+
+	fields := parsedFields(t, "rcparse.go", "buildinfo.go")
+	root := ResourceSchema()
+	for _, object := range objects {
+		parsed := make(map[string]bool)
+		for _, name := range object.TableFields {
+			parsed[name] = true
+		}
+		for _, reader := range object.Readers {
+			if fields[reader] == nil {
+				t.Errorf("%s reads no fields", reader)
+			}
+			for name := range fields[reader] {
+				parsed[name] = true
+			}
+			delete(fields, reader)
+		}
+		properties := schemaProperties(t, root, object.Path)
+		for name := range parsed {
+			if _, ok := properties[name]; !ok {
+				t.Errorf("field %s/%s is parsed but is not in the schema", object.Path, name)
+			}
+		}
+		for name := range properties {
+			// extends is handled when resource files are merged, before they are parsed
+			if !parsed[name] && !(object.Path == "" && name == "extends") {
+				t.Errorf("field %s/%s is in the schema but is not parsed", object.Path, name)
+			}
+		}
+	}
+	var unknown []string
+	for reader := range fields {
+		unknown = append(unknown, reader)
+	}
+	sort.Strings(unknown)
+	for _, reader := range unknown {
+		t.Errorf("%s reads fields that the test does not compare with the schema", reader)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ResourceSelector matches resources in an executable by type and, optionally, by name or ID and by language.
+type ResourceSelector struct {
+	Type        ResourceType // the predefined type, or zero if the type is given by TypeName
+	TypeName    string
+	Name        string // a resource name or decimal ID, or empty to match every resource of the type
+	Language    Language
+	HasLanguage bool // if false, resources in every language match
+}
+
+// ParseResourceSelector parses a selector of the form TYPE[/NAME[/LANGUAGE]].  TYPE is a predefined type such as
+// RT_MANIFEST, a type number or the name of a custom type, NAME is a resource name or ID or * for any resource, and
+// LANGUAGE is a locale name such as de-DE or a language ID such as 0x0407.
+func ParseResourceSelector(text string) (ResourceSelector, error) {
+	var sel ResourceSelector
+	parts := strings.Split(text, "/")
+	if len(parts) > 3 || parts[0] == "" {
+		return sel, errors.New(fmt.Sprintf("invalid resource selector %s (expected TYPE[/NAME[/LANGUAGE]])", text))
+	}
+	sel.Type, sel.TypeName = parseResourceType(parts[0])
+	if len(parts) > 1 && parts[1] != "*" {
+		if parts[1] == "" {
+			return sel, errors.New(fmt.Sprintf("invalid resource selector %s (missing name)", text))
+		}
+		sel.Name = parts[1]
+	}
+	if len(parts) > 2 && parts[2] != "*" {
+		language, err := LookupLanguage(parts[2])
+		if err != nil {
+			return sel, err
+		}
+		sel.Language, sel.HasLanguage = language, true
+	}
+	return sel, nil
+}
+
+func parseResourceType(text string) (ResourceType, string) {
+	for _, name := range resourceTypeNames {
+		if strings.EqualFold(name.WinName, text) {
+			return name.Type, ""
+		}
+	}
+	if value, err := strconv.ParseUint(text, 0, 16); err == nil && value != 0 {
+		return ResourceType(value), ""
+	}
+	return 0, text
+}
+
+func resourceTypeString(resourceType ResourceType, typeName string) string {
+	if typeName != "" {
+		return typeName
+	}
+	if name, ok := lookupResourceTypeName(resourceType); ok {
+		return name.WinName
+	}
+	return fmt.Sprintf("%d", resourceType)
+}
+
+// String returns the type, name or ID and language of the resource in the form accepted by ParseResourceSelector.
+func (res *ExecutableResource) String() string {
+	name := res.Name
+	if name == "" {
+		name = fmt.Sprintf("%d", res.Id)
+	}
+	return fmt.Sprintf("%s/%s/0x%04X", resourceTypeString(res.Type, res.TypeName), name, uint16(res.Language))
+}
+
+// sameResource reports whether two resources have the same type and name or ID, in any language.
+func sameResource(a *ExecutableResource, b *ExecutableResource) bool {
+	if a.Type != b.Type || !strings.EqualFold(a.TypeName, b.TypeName) {
+		return false
+	}
+	if a.Name != "" || b.Name != "" {
+		return strings.EqualFold(a.Name, b.Name)
+	}
+	return a.Id == b.Id
+}
+
+// Matches reports whether the selector matches the resource.
+func (sel ResourceSelector) Matches(res *ExecutableResource) bool {
+	if sel.Type != res.Type || !strings.EqualFold(sel.TypeName, res.TypeName) {
+		return false
+	}
+	if sel.Name != "" {
+		if res.Name != "" {
+			if !strings.EqualFold(sel.Name, res.Name) {
+				return false
+			}
+		} else if id, err := strconv.ParseUint(sel.Name, 0, 16); err != nil || uint(id) != res.Id {
+			return false
+		}
+	}
+	return !sel.HasLanguage || sel.Language == res.Language
+}
+
+// ResourcePolicy decides what happens to a new resource when the executable already has a resource with the same
+// type and name or ID.
+type ResourcePolicy uint32
+
+const (
+	// the new resource replaces the resources already in the executable, in its own language and in any other
+	PolicyReplace ResourcePolicy = iota
+	// the resources already in the executable are kept, and the new resource is not added
+	PolicyKeepExisting
+	// the update fails
+	PolicyFailOnConflict
+)
+
+var resourcePolicyNames = []namedValue{
+	{Name: "replace",          Value: uint32(PolicyReplace)},
+	{Name: "keep-existing",    Value: uint32(PolicyKeepExisting)},
+	{Name: "fail-on-conflict", Value: uint32(PolicyFailOnConflict)},
+}
+
+func (policy ResourcePolicy) String() string {
+	return lookupValueName(resourcePolicyNames, uint32(policy))
+}
+
+// PolicyRule applies a policy to the resources that a selector matches, or to every resource if Selector is nil.
+type PolicyRule struct {
+	Selector *ResourceSelector
+	Policy   ResourcePolicy
+}
+
+// ParsePolicyRule parses a rule of the form [SELECTOR=]POLICY, where POLICY is replace, keep-existing or
+// fail-on-conflict.
+func ParsePolicyRule(text string) (PolicyRule, error) {
+	var rule PolicyRule
+	policyName := text
+	if i := strings.LastIndexByte(text, '='); i >= 0 {
+		sel, err := ParseResourceSelector(text[:i])
+		if err != nil {
+			return rule, err
+		}
+		rule.Selector, policyName = &sel, text[i+1:]
+	}
+	policy, ok := lookupNamedValue(resourcePolicyNames, policyName)
+	if !ok {
+		return rule, errors.New(fmt.Sprintf("invalid policy %s (expected replace, keep-existing or fail-on-conflict)",
+			policyName))
+	}
+	rule.Policy = ResourcePolicy(policy)
+	return rule, nil
+}
+
+// policyFor returns the policy of the last rule that matches the resource.
+func policyFor(rules []PolicyRule, res *ExecutableResource) ResourcePolicy {
+	policy := PolicyReplace
+	for _, rule := range rules {
+		if rule.Selector == nil || rule.Selector.Matches(res) {
+			policy = rule.Policy
+		}
+	}
+	return policy
+}
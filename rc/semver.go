@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultSemverRule maps a semantic version onto a file version number by taking the major, minor and patch numbers
+// and the last numeric identifier of the build metadata.
+const DefaultSemverRule = "major.minor.patch.build"
+
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// SemanticVersion is a version string in the format defined by Semantic Versioning 2.0.0, optionally prefixed with v.
+type SemanticVersion struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      []string
+}
+
+func ParseSemanticVersion(s string) (*SemanticVersion, error) {
+	match := semverPattern.FindStringSubmatch(s)
+	if match == nil {
+		return nil, errors.New(fmt.Sprintf("invalid semantic version: %s", s))
+	}
+	var version SemanticVersion
+	var err error
+	if version.Major, err = strconv.ParseUint(match[1], 10, 64); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid semantic version: %s", s))
+	}
+	if version.Minor, err = strconv.ParseUint(match[2], 10, 64); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid semantic version: %s", s))
+	}
+	if version.Patch, err = strconv.ParseUint(match[3], 10, 64); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid semantic version: %s", s))
+	}
+	if match[4] != "" {
+		version.Prerelease = strings.Split(match[4], ".")
+	}
+	if match[5] != "" {
+		version.Build = strings.Split(match[5], ".")
+	}
+	return &version, nil
+}
+
+// lastNumericIdentifier returns the value of the last purely numeric identifier, or zero if there is none.
+func lastNumericIdentifier(identifiers []string) (uint64, error) {
+	for i := len(identifiers) - 1; i >= 0; i-- {
+		if n, err := strconv.ParseUint(identifiers[i], 10, 64); err == nil {
+			return n, nil
+		} else if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return 0, err
+		}
+	}
+	return 0, nil
+}
+
+// ValidateSemverRule checks a rule consisting of four dot-separated components, each of which is one of major,
+// minor, patch, prerelease (the last numeric prerelease identifier), build (the last numeric build metadata
+// identifier) or a literal number.
+func ValidateSemverRule(rule string) error {
+	components := strings.Split(rule, ".")
+	if len(components) != 4 {
+		return errors.New(fmt.Sprintf("semver rule must have four components: %s", rule))
+	}
+	for _, component := range components {
+		switch component {
+		case "major", "minor", "patch", "prerelease", "build":
+		default:
+			if _, err := strconv.ParseUint(component, 10, 16); err != nil {
+				return errors.New(fmt.Sprintf("invalid semver rule component: %s", component))
+			}
+		}
+	}
+	return nil
+}
+
+// FileVersion maps the semantic version onto the four parts of a file version number according to the rule.
+func (version *SemanticVersion) FileVersion(rule string) ([4]uint16, error) {
+	var parts [4]uint16
+	if err := ValidateSemverRule(rule); err != nil {
+		return parts, err
+	}
+	for i, component := range strings.Split(rule, ".") {
+		var value uint64
+		var err error
+		switch component {
+		case "major":
+			value = version.Major
+		case "minor":
+			value = version.Minor
+		case "patch":
+			value = version.Patch
+		case "prerelease":
+			value, err = lastNumericIdentifier(version.Prerelease)
+		case "build":
+			value, err = lastNumericIdentifier(version.Build)
+		default:
+			value, err = strconv.ParseUint(component, 10, 16)
+		}
+		if err != nil || value > 0xFFFF {
+			return parts, errors.New(fmt.Sprintf("%s component of version does not fit in 16 bits", component))
+		}
+		parts[i] = uint16(value)
+	}
+	return parts, nil
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseSemanticVersion(t *testing.T) {
+	for _, test := range []struct {
+		Text    string
+		Version *SemanticVersion
+	}{
+		{"1.2.3", &SemanticVersion{Major: 1, Minor: 2, Patch: 3}},
+		{"v0.10.0-rc.1", &SemanticVersion{Minor: 10, Prerelease: []string{"rc", "1"}}},
+		{"1.0.0-alpha-1.0+sha.5114f85.77", &SemanticVersion{Major: 1, Prerelease: []string{"alpha-1", "0"},
+			Build: []string{"sha", "5114f85", "77"}}},
+		{"1.0.0+001", &SemanticVersion{Major: 1, Build: []string{"001"}}},
+	} {
+		version, err := ParseSemanticVersion(test.Text)
+		if err != nil {
+			t.Errorf("%s: %s", test.Text, err)
+		} else if !reflect.DeepEqual(version, test.Version) {
+			t.Errorf("%s: got %+v, expected %+v", test.Text, version, test.Version)
+		}
+	}
+	for _, text := range []string{"1.2", "1.2.3.4", "01.2.3", "1.2.3-01", "1.2.3-", "1.2.3+", "1.2.3-a..b", "V1.2.3",
+		"99999999999999999999.0.0"} {
+		if _, err := ParseSemanticVersion(text); err == nil {
+			t.Errorf("%s was accepted", text)
+		}
+	}
+}
+
+func TestSemanticFileVersion(t *testing.T) {
+	for _, test := range []struct {
+		Text  string
+		Rule  string
+		Parts [4]uint16
+	}{
+		{"1.2.3", DefaultSemverRule, [4]uint16{1, 2, 3, 0}},
+		{"1.2.3+build.77", DefaultSemverRule, [4]uint16{1, 2, 3, 77}},
+		{"1.2.3+77.sha", DefaultSemverRule, [4]uint16{1, 2, 3, 77}},
+		{"1.2.3-rc.4+5", "major.minor.patch.prerelease", [4]uint16{1, 2, 3, 4}},
+		{"1.2.3-rc", "major.minor.patch.prerelease", [4]uint16{1, 2, 3, 0}},
+		{"4.5.6", "0.major.minor.65535", [4]uint16{0, 4, 5, 65535}},
+		{"65535.0.0", "major.major.patch.patch", [4]uint16{65535, 65535, 0, 0}},
+	} {
+		version, err := ParseSemanticVersion(test.Text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parts, err := version.FileVersion(test.Rule); err != nil {
+			t.Errorf("%s with %s: %s", test.Text, test.Rule, err)
+		} else if parts != test.Parts {
+			t.Errorf("%s with %s: got %v, expected %v", test.Text, test.Rule, parts, test.Parts)
+		}
+	}
+	for _, test := range []struct {
+		Text string
+		Rule string
+	}{
+		{"65536.0.0", DefaultSemverRule},
+		{"1.0.0+99999", DefaultSemverRule},
+		{"1.0.0-rc.99999999999999999999", "major.minor.patch.prerelease"},
+		{"1.0.0", "major.minor.patch"},
+		{"1.0.0", "major.minor.patch.revision"},
+		{"1.0.0", "major.minor.patch.65536"},
+	} {
+		version, err := ParseSemanticVersion(test.Text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parts, err := version.FileVersion(test.Rule); err == nil {
+			t.Errorf("%s with %s: got %v, expected an error", test.Text, test.Rule, parts)
+		}
+	}
+}
+
+// TestSemverVersionResource checks the file version, product version and flags that a semantic version sets in the
+// VS_FIXEDFILEINFO structure, which begins 40 bytes into the version resource.
+func TestSemverVersionResource(t *testing.T) {
+	for _, test := range []struct {
+		Json  string
+		Fixed string
+	}{
+		{
+			Json: `{"fileVersion": "3.1.0.77", "productVersion": "3.1"}`,
+			Fixed: `BD04EFFE | 00000000 | 01000300 | 4D000000 | 01000300 | 00000000 | 3F000000 | 00000000`,
+		},
+		{
+			Json: `{"fileVersion": "v1.2.3-rc.1+build.9", "productVersion": "1.2.3",
+				"semverRule": "major.minor.patch.build"}`,
+			Fixed: `BD04EFFE | 00000000 | 02000100 | 09000300 | 02000100 | 00000300 | 3F000000 | 02000000`,
+		},
+		{
+			Json: `{"fileVersion": "2.0.0-beta.7", "semverRule": "major.minor.prerelease.patch", "fileFlags": 1}`,
+			Fixed: `BD04EFFE | 00000000 | 00000200 | 00000700 | 00000000 | 00000000 | 3F000000 | 03000000`,
+		},
+	} {
+		data, err := parseTestResources(t, `{"version": `+test.Json+`}`, ResourceTypeVersion)
+		if err != nil {
+			t.Errorf("%s: %s", test.Json, err)
+			continue
+		}
+		expected := hexData(t, test.Fixed)
+		if len(data) != 1 || len(data[0]) < 40+len(expected) || !bytes.Equal(data[0][40:40+len(expected)], expected) {
+			t.Errorf("%s: got % X, expected VS_FIXEDFILEINFO to begin with % X", test.Json, data, expected)
+		}
+	}
+	for _, version := range []string{"1.2.3.4.5", "1.2.3.x", "1.2.3-rc+", "65536.0.0"} {
+		_, err := parseTestResources(t, `{"version": {"fileVersion": "`+version+`"}}`, ResourceTypeVersion)
+		if paths := errorPaths(err); !reflect.DeepEqual(paths, []string{"/version/fileVersion"}) {
+			t.Errorf("%s: errors at %q, expected /version/fileVersion (%v)", version, paths, err)
+		}
+	}
+}
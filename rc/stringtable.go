@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"unicode/utf16"
+)
+
+// stringsPerBlock is the number of strings in each RT_STRING resource.  The string with ID n is string n % 16 of the
+// resource with ID n / 16 + 1.
+const stringsPerBlock = 16
+
+// EncodeStringTable encodes strings, keyed by ID, as RT_STRING resources, one for each block of 16 IDs that contains
+// a string.  Each string is stored as its length in UTF-16 code units followed by the code units, without a
+// terminator, and the IDs in a block that have no string are stored as empty strings.  The resources are returned in
+// order of ID.
+func EncodeStringTable(strings map[uint16]string) []*Resource {
+	blocks := make(map[uint][stringsPerBlock]string)
+	for id, text := range strings {
+		block := blocks[uint(id)/stringsPerBlock+1]
+		block[id%stringsPerBlock] = text
+		blocks[uint(id)/stringsPerBlock+1] = block
+	}
+	blockIds := make([]uint, 0, len(blocks))
+	for blockId := range blocks {
+		blockIds = append(blockIds, blockId)
+	}
+	sort.Slice(blockIds, func(i, j int) bool {
+		return blockIds[i] < blockIds[j]
+	})
+	resources := make([]*Resource, 0, len(blockIds))
+	for _, blockId := range blockIds {
+		var buf bytes.Buffer
+		for _, text := range blocks[blockId] {
+			chars := utf16.Encode([]rune(text))
+			binary.Write(&buf, binary.LittleEndian, uint16(len(chars)))
+			binary.Write(&buf, binary.LittleEndian, chars)
+		}
+		resources = append(resources, &Resource{Type: ResourceTypeString, Id: blockId, Data: buf.Bytes()})
+	}
+	return resources
+}
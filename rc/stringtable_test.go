@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeStringTable(t *testing.T) {
+	// the length of each empty string in a block
+	empty := func(count int) []byte {
+		return make([]byte, 2*count)
+	}
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+	for _, test := range []struct {
+		Name    string
+		Strings map[uint16]string
+		Ids     []uint
+		Data    [][]byte
+	}{
+		{
+			Name:    "first",
+			Strings: map[uint16]string{0: "Hi"},
+			Ids:     []uint{1},
+			Data:    [][]byte{join([]byte{2, 0, 'H', 0, 'i', 0}, empty(15))},
+		},
+		{
+			Name:    "last in block",
+			Strings: map[uint16]string{31: "A"},
+			Ids:     []uint{2},
+			Data:    [][]byte{join(empty(15), []byte{1, 0, 'A', 0})},
+		},
+		{
+			Name:    "surrogate pair",
+			Strings: map[uint16]string{17: "\U0001F600"},
+			Ids:     []uint{2},
+			Data:    [][]byte{join(empty(1), []byte{2, 0, 0x3D, 0xD8, 0x00, 0xDE}, empty(14))},
+		},
+		{
+			Name:    "several blocks",
+			Strings: map[uint16]string{0xFFFF: "z", 1: "a", 2: "b"},
+			Ids:     []uint{1, 4096},
+			Data: [][]byte{
+				join(empty(1), []byte{1, 0, 'a', 0, 1, 0, 'b', 0}, empty(13)),
+				join(empty(15), []byte{1, 0, 'z', 0}),
+			},
+		},
+	} {
+		resources := EncodeStringTable(test.Strings)
+		if len(resources) != len(test.Ids) {
+			t.Errorf("%s: got %d resources, expected %d", test.Name, len(resources), len(test.Ids))
+			continue
+		}
+		for i, res := range resources {
+			if res.Type != ResourceTypeString || res.Id != test.Ids[i] || res.Name != "" {
+				t.Errorf("%s: resource %d is type %d, ID %d, expected type %d, ID %d", test.Name, i, res.Type, res.Id,
+					ResourceTypeString, test.Ids[i])
+			}
+			if !bytes.Equal(res.Data, test.Data[i]) {
+				t.Errorf("%s: resource %d is % X, expected % X", test.Name, i, res.Data, test.Data[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	imageScnCntInitializedData = 0x00000040
+	imageScnMemRead            = 0x40000000
+	imageSymClassStatic        = 3
+	imageResourceDataIsDir     = 0x80000000
+	imageResourceNameIsString  = 0x80000000
+)
+
+// COFF machine types and the relocation type that gives an address relative to the image base for each.  These are
+// the architectures for which the Go linker accepts .syso files containing resources.
+var sysoMachines = map[string]struct {
+	Machine        uint16
+	RelocationType uint16
+}{
+	"386":   {Machine: pe.IMAGE_FILE_MACHINE_I386,  RelocationType: 0x0007}, // IMAGE_REL_I386_DIR32NB
+	"amd64": {Machine: pe.IMAGE_FILE_MACHINE_AMD64, RelocationType: 0x0003}, // IMAGE_REL_AMD64_ADDR32NB
+	"arm64": {Machine: pe.IMAGE_FILE_MACHINE_ARM64, RelocationType: 0x0002}, // IMAGE_REL_ARM64_ADDR32NB
+}
+
+type resourceDirectory struct {
+	Characteristics      uint32
+	TimeDateStamp        uint32
+	MajorVersion         uint16
+	MinorVersion         uint16
+	NumberOfNamedEntries uint16
+	NumberOfIdEntries    uint16
+}
+
+type resourceDirectoryEntry struct {
+	Name         uint32
+	OffsetToData uint32
+}
+
+type resourceDataEntry struct {
+	OffsetToData uint32
+	Size         uint32
+	CodePage     uint32
+	Reserved     uint32
+}
+
+// encodeResourceSection lays out a resource directory for the resources, as it appears in the .rsrc section of an
+// image: the directory tables for types, names and languages, then the data entries, the resource names and the
+// resource data.  The offsets of data within the section are returned along with the offsets of the fields that
+// hold them, which must be relocated to addresses relative to the image base.
+func encodeResourceSection(resources []*Resource, language uint16) ([]byte, []uint32, error) {
+	resources = append([]*Resource(nil), resources...)
+	SortResources(resources)
+	var types [][]*Resource
+	for _, res := range resources {
+		if _, err := resourceTypeOrdinal(res); err != nil {
+			return nil, nil, err
+		}
+		if res.Name == "" && res.Id > 0xFFFF {
+			return nil, nil, errors.New(fmt.Sprintf("invalid resource ID %d", res.Id))
+		}
+		if len(types) > 0 && types[len(types)-1][0].Type == res.Type {
+			types[len(types)-1] = append(types[len(types)-1], res)
+		} else {
+			types = append(types, []*Resource{res})
+		}
+	}
+
+	directorySize := func(entries int) uint32 {
+		return uint32(binary.Size(resourceDirectory{}) + entries*binary.Size(resourceDirectoryEntry{}))
+	}
+	offset := directorySize(len(types))
+	typeOffsets := make([]uint32, len(types))
+	for i, typeResources := range types {
+		typeOffsets[i] = offset
+		offset += directorySize(len(typeResources))
+	}
+	languageOffsets := make([]uint32, len(resources))
+	for i := range resources {
+		languageOffsets[i] = offset
+		offset += directorySize(1)
+	}
+	dataEntryOffsets := make([]uint32, len(resources))
+	for i := range resources {
+		dataEntryOffsets[i] = offset
+		offset += uint32(binary.Size(resourceDataEntry{}))
+	}
+	var names bytes.Buffer
+	nameOffsets := make([]uint32, len(resources))
+	for i, res := range resources {
+		if res.Name != "" {
+			nameOffsets[i] = offset + uint32(names.Len())
+			var name bytes.Buffer
+			writeUTF16String(&name, res.Name)
+			// the name is counted rather than null-terminated
+			binary.Write(&names, binary.LittleEndian, uint16(name.Len()/2-1))
+			names.Write(name.Bytes()[:name.Len()-2])
+		}
+	}
+	alignBuffer(&names, 8)
+	offset += uint32(names.Len())
+	dataOffsets := make([]uint32, len(resources))
+	for i, res := range resources {
+		dataOffsets[i] = offset
+		offset += uint32(len(res.Data)+7) &^ 7
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, resourceDirectory{NumberOfIdEntries: uint16(len(types))})
+	for i, typeResources := range types {
+		binary.Write(&buf, binary.LittleEndian, resourceDirectoryEntry{
+			Name:         uint32(typeResources[0].Type),
+			OffsetToData: imageResourceDataIsDir | typeOffsets[i],
+		})
+	}
+	index := 0
+	for _, typeResources := range types {
+		var named uint16
+		for _, res := range typeResources {
+			if res.Name != "" {
+				named++
+			}
+		}
+		binary.Write(&buf, binary.LittleEndian, resourceDirectory{
+			NumberOfNamedEntries: named,
+			NumberOfIdEntries:    uint16(len(typeResources)) - named,
+		})
+		for _, res := range typeResources {
+			entry := resourceDirectoryEntry{OffsetToData: imageResourceDataIsDir | languageOffsets[index]}
+			if res.Name != "" {
+				entry.Name = imageResourceNameIsString | nameOffsets[index]
+			} else {
+				entry.Name = uint32(res.Id)
+			}
+			binary.Write(&buf, binary.LittleEndian, entry)
+			index++
+		}
+	}
+	for i := range resources {
+		binary.Write(&buf, binary.LittleEndian, resourceDirectory{NumberOfIdEntries: 1})
+		binary.Write(&buf, binary.LittleEndian, resourceDirectoryEntry{
+			Name:         uint32(language),
+			OffsetToData: dataEntryOffsets[i],
+		})
+	}
+	relocations := make([]uint32, 0, len(resources))
+	for i, res := range resources {
+		relocations = append(relocations, uint32(buf.Len()))
+		binary.Write(&buf, binary.LittleEndian, resourceDataEntry{
+			OffsetToData: dataOffsets[i],
+			Size:         uint32(len(res.Data)),
+		})
+	}
+	buf.Write(names.Bytes())
+	for _, res := range resources {
+		buf.Write(res.Data)
+		alignBuffer(&buf, 8)
+	}
+	return buf.Bytes(), relocations, nil
+}
+
+// WriteSyso writes the resources as a COFF object file that the Go linker includes in Windows executables when it is
+// placed in the main package directory with a name such as rsrc_windows_amd64.syso.  The architecture is given by
+// its GOARCH name.
+func (set *ResourceSet) WriteSyso(w io.Writer, arch string) error {
+	machine, ok := sysoMachines[arch]
+	if !ok {
+		return errors.New(fmt.Sprintf("unsupported architecture for .syso file: %s", arch))
+	}
+	section, relocations, err := encodeResourceSection(set.Resources, uint16(set.Language))
+	if err != nil {
+		return err
+	}
+	headersSize := uint32(binary.Size(pe.FileHeader{}) + binary.Size(pe.SectionHeader32{}))
+	relocationsOffset := headersSize + uint32(len(section))
+	symbolsOffset := relocationsOffset + uint32(len(relocations)*binary.Size(pe.Reloc{}))
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, pe.FileHeader{
+		Machine:              machine.Machine,
+		NumberOfSections:     1,
+		PointerToSymbolTable: symbolsOffset,
+		NumberOfSymbols:      1,
+	})
+	binary.Write(&buf, binary.LittleEndian, pe.SectionHeader32{
+		Name:                 [8]uint8{'.', 'r', 's', 'r', 'c'},
+		SizeOfRawData:        uint32(len(section)),
+		PointerToRawData:     headersSize,
+		PointerToRelocations: relocationsOffset,
+		NumberOfRelocations:  uint16(len(relocations)),
+		Characteristics:      imageScnCntInitializedData | imageScnMemRead,
+	})
+	buf.Write(section)
+	for _, relocation := range relocations {
+		binary.Write(&buf, binary.LittleEndian, pe.Reloc{
+			VirtualAddress:   relocation,
+			SymbolTableIndex: 0,
+			Type:             machine.RelocationType,
+		})
+	}
+	// the relocations refer to the section symbol, and the data entries hold offsets from it
+	binary.Write(&buf, binary.LittleEndian, pe.COFFSymbol{
+		Name:          [8]uint8{'.', 'r', 's', 'r', 'c'},
+		SectionNumber: 1,
+		StorageClass:  imageSymClassStatic,
+	})
+	// an empty string table consists of its own length
+	binary.Write(&buf, binary.LittleEndian, uint32(4))
+	_, err = w.Write(buf.Bytes())
+	return err
+}
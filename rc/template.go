@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the license is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// GitInfo describes the state of the Git working tree containing the resource file.
+type GitInfo struct {
+	Commit      string
+	ShortCommit string
+	Tag         string
+	Branch      string
+	Dirty       bool
+}
+
+// TemplateData is the data against which templates in string values are evaluated.  Env holds the environment
+// variables, Define holds the definitions given with -D on the command line, and Date is the build date, which is taken
+// from SOURCE_DATE_EPOCH if it is set.  Git metadata is read on first use.
+type TemplateData struct {
+	Env    map[string]string
+	Define map[string]string
+	Date   time.Time
+
+	gitDir  string
+	gitOnce sync.Once
+	gitInfo *GitInfo
+	gitErr  error
+}
+
+// NewTemplateData collects the template variables, reading Git metadata from the given directory when it is needed.
+func NewTemplateData(defines map[string]string, gitDir string) (*TemplateData, error) {
+	date, err := BuildDate()
+	if err != nil {
+		return nil, err
+	}
+	data := TemplateData{
+		Env:    make(map[string]string),
+		Define: defines,
+		Date:   date,
+		gitDir: gitDir,
+	}
+	if data.Define == nil {
+		data.Define = make(map[string]string)
+	}
+	for _, env := range os.Environ() {
+		if i := strings.IndexByte(env, '='); i > 0 {
+			data.Env[env[:i]] = env[i+1:]
+		}
+	}
+	return &data, nil
+}
+
+// BuildDate returns the build date: the time given by SOURCE_DATE_EPOCH if it is set, or else the current time.
+func BuildDate() (time.Time, error) {
+	if epoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok {
+		seconds, err := ParseSourceDateEpoch(epoch)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return time.Now().UTC(), nil
+}
+
+// ParseSourceDateEpoch parses the value of the SOURCE_DATE_EPOCH environment variable, which reproducible builds
+// set to the number of seconds since 1970 to use in place of the current time.  The value must fit in the 32-bit
+// timestamps of a PE file, so it may be at most 4294967295.
+func ParseSourceDateEpoch(epoch string) (int64, error) {
+	seconds, err := strconv.ParseUint(epoch, 10, 32)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("invalid SOURCE_DATE_EPOCH: %s (must be a number of seconds from 0 to %d)",
+			epoch, uint32(math.MaxUint32)))
+	}
+	return int64(seconds), nil
+}
+
+func (data *TemplateData) runGit(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = data.gitDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Git returns the metadata of the Git working tree, or an error if it is not available.
+func (data *TemplateData) Git() (*GitInfo, error) {
+	data.gitOnce.Do(func() {
+		var info GitInfo
+		if info.Commit, data.gitErr = data.runGit("rev-parse", "HEAD"); data.gitErr != nil {
+			data.gitErr = errors.New(fmt.Sprintf("could not read Git metadata (%s)", data.gitErr))
+			return
+		}
+		if len(info.Commit) >= 12 {
+			info.ShortCommit = info.Commit[:12]
+		}
+		info.Tag, _ = data.runGit("describe", "--tags", "--exact-match")
+		info.Branch, _ = data.runGit("rev-parse", "--abbrev-ref", "HEAD")
+		status, _ := data.runGit("status", "--porcelain", "--untracked-files=no")
+		info.Dirty = status != ""
+		data.gitInfo = &info
+	})
+	return data.gitInfo, data.gitErr
+}
+
+// escapeJSONPointer escapes a key for use as a JSON pointer reference token, as described in RFC 6901.
+func escapeJSONPointer(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
+
+func expandTemplateValue(value interface{}, path string, data *TemplateData) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v, nil
+		}
+		tmpl, err := template.New(path).Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, &PathError{Path: path, Err: errors.New(fmt.Sprintf("invalid template (%s)", err))}
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, &PathError{Path: path, Err: errors.New(fmt.Sprintf("could not expand template (%s)", err))}
+		}
+		return buf.String(), nil
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		var errs ErrorList
+		for key, elem := range v {
+			expanded, err := expandTemplateValue(elem, path+"/"+escapeJSONPointer(key), data)
+			if err != nil {
+				errs.add(err)
+			}
+			result[key] = expanded
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		var errs ErrorList
+		for i, elem := range v {
+			expanded, err := expandTemplateValue(elem, fmt.Sprintf("%s/%d", path, i), data)
+			if err != nil {
+				errs.add(err)
+			}
+			result[i] = expanded
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// ExpandTemplates evaluates every string value in the JSON data that contains "{{" as a Go text/template.  All
+// failures are returned together as an ErrorList of PathErrors naming the JSON pointer of each offending value.
+func ExpandTemplates(jsonData map[string]interface{}, data *TemplateData) (map[string]interface{}, error) {
+	expanded, err := expandTemplateValue(jsonData, "", data)
+	if err != nil {
+		return nil, err
+	}
+	return expanded.(map[string]interface{}), nil
+}
@@ -0,0 +1,33 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package rc
+
+import (
+	"testing"
+)
+
+func TestParseSourceDateEpoch(t *testing.T) {
+	for _, epoch := range []string{"0", "1700000000", "4294967295"} {
+		if _, err := ParseSourceDateEpoch(epoch); err != nil {
+			t.Errorf("SOURCE_DATE_EPOCH=%s: %s", epoch, err)
+		}
+	}
+	for _, epoch := range []string{"", "-1", "4294967296", "1e9", "soon"} {
+		if _, err := ParseSourceDateEpoch(epoch); err == nil {
+			t.Errorf("SOURCE_DATE_EPOCH=%s was accepted", epoch)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Language is a Windows language identifier (LANGID), such as 0x0409 for English (United States).
+type Language uint16
+
+// LanguageNeutral is the language of resources that do not depend on the language of the user.
+const LanguageNeutral Language = 0
+
+// ResourceType is a predefined resource type, identified by the same ordinal as the RT_ constants of Windows.
+type ResourceType uint16
+
+const (
+	ResourceTypeCursor        ResourceType = 1
+	ResourceTypeBitmap        ResourceType = 2
+	ResourceTypeIcon          ResourceType = 3
+	ResourceTypeMenu          ResourceType = 4
+	ResourceTypeDialog        ResourceType = 5
+	ResourceTypeString        ResourceType = 6
+	ResourceTypeFontDir       ResourceType = 7
+	ResourceTypeFont          ResourceType = 8
+	ResourceTypeAccelerator   ResourceType = 9
+	ResourceTypeRCData        ResourceType = 10
+	ResourceTypeMessageTable  ResourceType = 11
+	ResourceTypeGroupCursor   ResourceType = 12
+	ResourceTypeGroupIcon     ResourceType = 14
+	ResourceTypeVersion       ResourceType = 16
+	ResourceTypeDialogInclude ResourceType = 17
+	ResourceTypePlugPlay      ResourceType = 19
+	ResourceTypeVxD           ResourceType = 20
+	ResourceTypeAniCursor     ResourceType = 21
+	ResourceTypeAniIcon       ResourceType = 22
+	ResourceTypeHTML          ResourceType = 23
+	ResourceTypeManifest      ResourceType = 24
+)
+
+// LookupLanguage returns the language identifier for a locale name such as en-US, or for a language identifier given
+// as a number such as 0x0409, which is accepted on every system.
+func LookupLanguage(name string) (Language, error) {
+	if value, err := strconv.ParseUint(name, 0, 16); err == nil {
+		return Language(value), nil
+	}
+	language, err := lookupLocaleName(name)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("invalid language %s (%s)", name, err))
+	}
+	return language, nil
+}
+
+// makeLong combines two 16-bit values into a 32-bit value, like the MAKELONG macro.
+func makeLong(low uint16, high uint16) uint32 {
+	return uint32(low) | uint32(high)<<16
+}
+
+// parseFileVersionNumber parses a version number of up to four dotted parts, such as 3.1.0.77, into its parts.  Parts
+// that are not given are zero, and more than four parts are an error.
+func parseFileVersionNumber(text string) ([4]uint16, error) {
+	var parts [4]uint16
+	components := strings.Split(text, ".")
+	if len(components) > len(parts) {
+		return parts, errors.New(fmt.Sprintf("version number has more than four parts: %s", text))
+	}
+	for i, part := range components {
+		value, err := strconv.ParseUint(part, 10, 16)
+		if err != nil {
+			return parts, err
+		}
+		parts[i] = uint16(value)
+	}
+	return parts, nil
+}
@@ -14,17 +14,92 @@
  * limitations under the License.
  */
 
-package main
+package rc
 
 import (
-	"github.com/winlabs/gowin32"
-	"github.com/winlabs/gowin32/wrappers"
-
 	"fmt"
-	"syscall"
+	"unicode/utf16"
 	"unsafe"
 )
 
+const (
+	VS_FFI_SIGNATURE     = 0xFEEF04BD
+	VS_FFI_STRUCVERSION  = 0x00010000
+	VS_FFI_FILEFLAGSMASK = 0x0000003F
+)
+
+// The following constants and VS_FIXEDFILEINFO are defined as in the Win32 header verrsrc.h.
+
+const (
+	VS_FF_DEBUG        = 0x00000001
+	VS_FF_PRERELEASE   = 0x00000002
+	VS_FF_PATCHED      = 0x00000004
+	VS_FF_PRIVATEBUILD = 0x00000008
+	VS_FF_INFOINFERRED = 0x00000010
+	VS_FF_SPECIALBUILD = 0x00000020
+)
+
+const (
+	VOS_UNKNOWN       = 0x00000000
+	VOS_DOS           = 0x00010000
+	VOS_OS216         = 0x00020000
+	VOS_OS232         = 0x00030000
+	VOS_NT            = 0x00040000
+	VOS__WINDOWS16    = 0x00000001
+	VOS__PM16         = 0x00000002
+	VOS__PM32         = 0x00000003
+	VOS__WINDOWS32    = 0x00000004
+	VOS_DOS_WINDOWS16 = 0x00010001
+	VOS_DOS_WINDOWS32 = 0x00010004
+	VOS_OS216_PM16    = 0x00020002
+	VOS_OS232_PM32    = 0x00030003
+	VOS_NT_WINDOWS32  = 0x00040004
+)
+
+const (
+	VFT_UNKNOWN    = 0x00000000
+	VFT_APP        = 0x00000001
+	VFT_DLL        = 0x00000002
+	VFT_DRV        = 0x00000003
+	VFT_FONT       = 0x00000004
+	VFT_VXD        = 0x00000005
+	VFT_STATIC_LIB = 0x00000007
+)
+
+const (
+	VFT2_UNKNOWN               = 0x00000000
+	VFT2_DRV_PRINTER           = 0x00000001
+	VFT2_DRV_KEYBOARD          = 0x00000002
+	VFT2_DRV_LANGUAGE          = 0x00000003
+	VFT2_DRV_DISPLAY           = 0x00000004
+	VFT2_DRV_MOUSE             = 0x00000005
+	VFT2_DRV_NETWORK           = 0x00000006
+	VFT2_DRV_SYSTEM            = 0x00000007
+	VFT2_DRV_INSTALLABLE       = 0x00000008
+	VFT2_DRV_SOUND             = 0x00000009
+	VFT2_DRV_COMM              = 0x0000000A
+	VFT2_DRV_VERSIONED_PRINTER = 0x0000000C
+	VFT2_FONT_RASTER           = 0x00000001
+	VFT2_FONT_VECTOR           = 0x00000002
+	VFT2_FONT_TRUETYPE         = 0x00000003
+)
+
+type VS_FIXEDFILEINFO struct {
+	Signature        uint32
+	StrucVersion     uint32
+	FileVersionMS    uint32
+	FileVersionLS    uint32
+	ProductVersionMS uint32
+	ProductVersionLS uint32
+	FileFlagsMask    uint32
+	FileFlags        uint32
+	FileOS           uint32
+	FileType         uint32
+	FileSubtype      uint32
+	FileDateMS       uint32
+	FileDateLS       uint32
+}
+
 type VersionString struct {
 	Key   string
 	Value string
@@ -39,7 +114,7 @@ type vsVersionInfo struct {
 	Type        uint16
 	Key         [16]uint16
 	Padding     uint16
-	Value       wrappers.VS_FIXEDFILEINFO
+	Value       VS_FIXEDFILEINFO
 }
 
 type vsStringFileInfo struct {
@@ -81,7 +156,7 @@ type vsVar struct {
 
 func stringToUTF16Bytes(text string) []byte {
 	data := make([]byte, 2*len(text))
-	wrappers.RtlMoveMemory(&data[0], (*byte)(unsafe.Pointer(syscall.StringToUTF16Ptr(text))), uintptr(len(data)))
+	putUTF16String(data, text)
 	return data
 }
 
@@ -98,14 +173,14 @@ func encodeString(key string, value string) []byte {
 	info.ValueLength = uint16(len(value)) + 1
 	info.Type = 1
 	data := make([]byte, unsafe.Sizeof(info))
-	wrappers.RtlMoveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
+	moveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
 	data = append(data, stringToUTF16Bytes(key)...)
 	data = append(data, make([]byte, 2 + paddingBytes1)...)
 	data = append(data, stringToUTF16Bytes(value)...)
 	return append(data, make([]byte, 2 + paddingBytes2)...)
 }
 
-func encodeStringTable(language gowin32.Language, codePage uint32, stringInfo []VersionString) []byte {
+func encodeStringTable(language Language, codePage uint32, stringInfo []VersionString) []byte {
 	extraData := make([]byte, 0, 1024)
 	for _, pair := range stringInfo {
 		extraData = append(extraData, encodeString(pair.Key, pair.Value)...)
@@ -114,59 +189,59 @@ func encodeStringTable(language gowin32.Language, codePage uint32, stringInfo []
 	info.Length = uint16(unsafe.Sizeof(info)) + uint16(len(extraData))
 	info.ValueLength = 0
 	info.Type = 1
-	copy(info.Key[:], syscall.StringToUTF16(fmt.Sprintf("%04X%04X", uint16(language), uint16(codePage))))
+	copy(info.Key[:], utf16.Encode([]rune(fmt.Sprintf("%04X%04X", uint16(language), uint16(codePage)))))
 	data := make([]byte, unsafe.Sizeof(info))
-	wrappers.RtlMoveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
+	moveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
 	return append(data, extraData...)
 }
 
-func encodeStringFileInfo(language gowin32.Language, codePage uint32, stringInfo []VersionString) []byte {
+func encodeStringFileInfo(language Language, codePage uint32, stringInfo []VersionString) []byte {
 	extraData := encodeStringTable(language, codePage, stringInfo)
 	var info vsStringFileInfo
 	info.Length = uint16(unsafe.Sizeof(info)) + uint16(len(extraData))
 	info.ValueLength = 0
 	info.Type = 1
-	copy(info.Key[:], syscall.StringToUTF16("StringFileInfo"))
+	copy(info.Key[:], utf16.Encode([]rune("StringFileInfo")))
 	data := make([]byte, unsafe.Sizeof(info))
-	wrappers.RtlMoveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
+	moveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
 	return append(data, extraData...)
 }
 
-func encodeTranslation(language gowin32.Language, codePage uint32) []byte {
+func encodeTranslation(language Language, codePage uint32) []byte {
 	var info vsVar
 	info.Length = uint16(unsafe.Sizeof(info))
 	info.ValueLength = uint16(unsafe.Sizeof(info.Value))
 	info.Type = 0
-	copy(info.Key[:], syscall.StringToUTF16("Translation"))
-	info.Value = wrappers.MAKELONG(uint16(language), uint16(codePage))
+	copy(info.Key[:], utf16.Encode([]rune("Translation")))
+	info.Value = makeLong(uint16(language), uint16(codePage))
 	data := make([]byte, unsafe.Sizeof(info))
-	wrappers.RtlMoveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
+	moveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
 	return data
 }
 
-func encodeVarFileInfo(language gowin32.Language, codePage uint32) []byte {
+func encodeVarFileInfo(language Language, codePage uint32) []byte {
 	extraData := encodeTranslation(language, codePage)
 	var info vsVarFileInfo
 	info.Length = uint16(unsafe.Sizeof(info)) + uint16(len(extraData))
 	info.ValueLength = 0
 	info.Type = 1
-	copy(info.Key[:], syscall.StringToUTF16("VarFileInfo"))
+	copy(info.Key[:], utf16.Encode([]rune("VarFileInfo")))
 	data := make([]byte, unsafe.Sizeof(info))
-	wrappers.RtlMoveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
+	moveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
 	return append(data, extraData...)
 }
 
-func EncodeVersionInfo(fixedInfo *wrappers.VS_FIXEDFILEINFO, language gowin32.Language, codePage uint32, stringInfo []VersionString) []byte {
+func EncodeVersionInfo(fixedInfo *VS_FIXEDFILEINFO, language Language, codePage uint32, stringInfo []VersionString) []byte {
 	stringData := encodeStringFileInfo(language, codePage, stringInfo)
 	varData := encodeVarFileInfo(language, codePage)
 	var info vsVersionInfo
 	info.Length = uint16(unsafe.Sizeof(info)) + uint16(len(stringData)) + uint16(len(varData))
 	info.ValueLength = uint16(unsafe.Sizeof(info.Value))
 	info.Type = 0
-	copy(info.Key[:], syscall.StringToUTF16("VS_VERSION_INFO"))
+	copy(info.Key[:], utf16.Encode([]rune("VS_VERSION_INFO")))
 	info.Value = *fixedInfo
 	data := make([]byte, unsafe.Sizeof(info))
-	wrappers.RtlMoveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
+	moveMemory(&data[0], (*byte)(unsafe.Pointer(&info)), unsafe.Sizeof(info))
 	data = append(data, stringData...)
 	return append(data, varData...)
 }
@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2014 MongoDB, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVersionErrors(t *testing.T) {
+	for _, test := range []struct {
+		Json    string
+		Paths   []string
+		Message string
+	}{
+		{`{"fileVersion": "1.0", "compnyName": "MongoDB"}`, []string{"/version/compnyName"},
+			"invalid version field compnyName"},
+		{`{"stringFileInfo": {"compnyName": "MongoDB", "custom": {"Build": "77"}}}`,
+			[]string{"/version/stringFileInfo/compnyName"}, "use custom for other strings"},
+		{`{"fileVersion": "1.0.0-rc.1", "fileFlagsMask": 1}`, []string{"/version/fileFlagsMask"},
+			"VS_FF_PRERELEASE, which the prerelease version 1.0.0-rc.1 sets"},
+		{`{"productVersion": "2.0.0-beta", "fileFlagsMask": ["VS_FF_DEBUG"], "fileFlags": ["VS_FF_DEBUG"]}`,
+			[]string{"/version/fileFlagsMask"}, "prerelease version 2.0.0-beta"},
+		{`{"fileFlags": 4, "fileFlagsMask": 1}`, []string{"/version/fileFlags"}, "not in fileFlagsMask"},
+	} {
+		_, err := parseTestResources(t, `{"version": `+test.Json+`}`, ResourceTypeVersion)
+		if err == nil {
+			t.Errorf("%s: no error", test.Json)
+		} else if paths := errorPaths(err); !reflect.DeepEqual(paths, test.Paths) {
+			t.Errorf("%s: errors at %q, expected %q (%s)", test.Json, paths, test.Paths, err)
+		} else if !strings.Contains(err.Error(), test.Message) {
+			t.Errorf("%s: got error %q, expected it to mention %q", test.Json, err, test.Message)
+		}
+	}
+
+	data, err := parseTestResources(t, `{"version": {"fileVersion": "1.0.0-rc.1", "fileFlagsMask": 3}}`,
+		ResourceTypeVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// dwFileFlagsMask and dwFileFlags lie 24 and 28 bytes into VS_FIXEDFILEINFO, which begins 40 bytes into the resource
+	if expected := hexData(t, "03000000 | 02000000"); len(data) != 1 || !reflect.DeepEqual(data[0][64:72], expected) {
+		t.Errorf("got % X, expected the flags % X", data, expected)
+	}
+}